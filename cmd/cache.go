@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/cache"
+	"github.com/ankit-lilly/nqcli/internal/config"
+)
+
+var (
+	// noCache turns the result cache off for one command.
+	noCache bool
+	// cacheTTL turns the result cache on for one command, overriding the
+	// environment's TTL.
+	cacheTTL time.Duration
+	// cacheInMemory keeps results in memory instead of on disk. Long-running
+	// commands (server, mcp, shell) set it before building their service.
+	cacheInMemory bool
+
+	memoryCacheOnce sync.Once
+	memoryCache     *cache.Cache
+)
+
+// resultCache returns the result cache cfg asks for, or nil when caching is
+// off. Services built in one process share a single memory cache, sized by
+// the first configuration that enables it; entries are keyed by endpoint, so
+// reconnecting the shell to another environment does not mix results.
+func resultCache(cfg *config.Config) *cache.Cache {
+	if noCache || cfg.CacheTTL <= 0 {
+		return nil
+	}
+	opts := cache.Options{TTL: cfg.CacheTTL, MaxBytes: cfg.CacheMaxBytes}
+	if cacheInMemory {
+		memoryCacheOnce.Do(func() {
+			memoryCache = cache.NewMemory(opts)
+		})
+		return memoryCache
+	}
+
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		// Without a cache directory queries simply run uncached.
+		return nil
+	}
+	return cache.NewDisk(dir, opts)
+}
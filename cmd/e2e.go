@@ -52,7 +52,7 @@ Examples:
 				CleanupOnly: cleanupOnly,
 			}
 
-			report := e2e.Run(client, cfg)
+			report := e2e.Run(cmd.Context(), client, cfg)
 			e2e.PrintReport(report)
 
 			if report.HasFailures() {
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ankit-lilly/nqcli/internal/lint"
+	"github.com/ankit-lilly/nqcli/internal/schema"
+
+	"github.com/charmbracelet/log"
+)
+
+// noLint disables the schema check of Gremlin queries before they run.
+var noLint bool
+
+// queryLinter checks queries against the dynamic schema cached for
+// appService's endpoint, or against static (nil selects the embedded schema)
+// when none is cached. It never runs discovery, so linting costs no queries.
+func queryLinter(appService queryService, static *schema.StaticDoc) *lint.Linter {
+	if g := dynamicSchemaCache.Cached(appService); g != nil {
+		return lint.New(g)
+	}
+	if static == nil {
+		var err error
+		if static, err = schema.ParseStatic(); err != nil {
+			return nil
+		}
+	}
+	return lint.New(static.Graph())
+}
+
+// logQueryLint writes schema warnings for query to l. Linting never stops a
+// query: a static schema that fails to load is reported and the check is
+// skipped.
+func logQueryLint(l *log.Logger, appService queryService, query, queryType string) {
+	if !strings.EqualFold(queryType, "gremlin") {
+		return
+	}
+	static, err := loadStaticSchema()
+	if err != nil {
+		l.Warn("skipping schema lint", "error", err)
+		return
+	}
+	for _, warning := range queryLinter(appService, static).Check(query, queryType) {
+		l.Warn(warning.Message)
+	}
+}
+
+// lintNote explains warning messages to an MCP client alongside the query
+// result or error.
+func lintNote(warnings []string) string {
+	var b strings.Builder
+	b.WriteString("Schema warnings: the query names things the graph schema does not contain, which may explain missing results or errors.")
+	for _, warning := range warnings {
+		fmt.Fprintf(&b, "\n- %s", warning)
+	}
+	return b.String()
+}
@@ -3,14 +3,61 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/lint"
+	"github.com/ankit-lilly/nqcli/internal/params"
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/ankit-lilly/nqcli/internal/schema"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
 
+// readOnlyNote tells MCP clients whether appService accepts queries that
+// modify the graph. Read-only mode may come from --read-only, NQ_READ_ONLY or
+// the config file, so the note reports the effective setting.
+func readOnlyNote(appService queryService) string {
+	service, ok := appService.(interface{ ReadOnly() bool })
+	switch {
+	case !ok:
+		return "Queries that modify the graph are rejected when the server is read-only."
+	case service.ReadOnly():
+		return "This server is read-only: queries that modify the graph are rejected."
+	}
+	return "This server is not read-only: queries may modify the graph."
+}
+
+const explainNote = "Set explain to see how Neptune would run a slow query without running it, or profile to run it and see where the time goes."
+
 type runGremlinArgs struct {
-	Query string `json:"query" jsonschema:"The Gremlin traversal string to execute"`
+	Query    string         `json:"query" jsonschema:"The Gremlin traversal string to execute"`
+	Bindings map[string]any `json:"bindings,omitempty" jsonschema:"Optional values for $name placeholders in the query; rendered as safely quoted Gremlin literals"`
+	Explain  bool           `json:"explain,omitempty" jsonschema:"Return Neptune's query plan instead of running the query"`
+	Profile  bool           `json:"profile,omitempty" jsonschema:"Run the query and return Neptune's profile (plan with per-step timings and counts) instead of the result"`
+}
+
+type runCypherArgs struct {
+	Query    string         `json:"query" jsonschema:"The openCypher query to execute"`
+	Bindings map[string]any `json:"bindings,omitempty" jsonschema:"Optional values for $name placeholders in the query; rendered as safely quoted Cypher literals"`
+	Explain  bool           `json:"explain,omitempty" jsonschema:"Return Neptune's query plan instead of running the query"`
+	Profile  bool           `json:"profile,omitempty" jsonschema:"Run the query and return Neptune's profile (plan with per-step timings and counts) instead of the result"`
+}
+
+type runQueryArgs struct {
+	Language string         `json:"language" jsonschema:"Query language of the query: gremlin or cypher"`
+	Query    string         `json:"query" jsonschema:"The Gremlin traversal or openCypher query to execute"`
+	Bindings map[string]any `json:"bindings,omitempty" jsonschema:"Optional values for $name placeholders in the query; rendered as safely quoted literals for the chosen language"`
+	Explain  bool           `json:"explain,omitempty" jsonschema:"Return Neptune's query plan instead of running the query"`
+	Profile  bool           `json:"profile,omitempty" jsonschema:"Run the query and return Neptune's profile (plan with per-step timings and counts) instead of the result"`
 }
 
 func init() {
@@ -19,69 +66,315 @@ func init() {
 
 func newMcpCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "mcp",
-		Short:         "Start an MCP server over stdio for running Neptune queries.",
+		Use:   "mcp",
+		Short: "Start an MCP server for running Neptune queries.",
+		Long: `Start an MCP server for running Neptune queries.
+
+By default the server speaks MCP over stdio and is launched by the MCP client.
+With --transport http it serves the streamable HTTP transport at /mcp so one
+shared instance can serve a team. HTTP clients must send
+"Authorization: Bearer <token>" matching --auth-token (or $` + mcpAuthTokenEnvVar + `).`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			appService, err := newQueryService(cmd.Context())
+			transport, err := cmd.Flags().GetString("transport")
+			if err != nil {
+				return err
+			}
+			addr, err := cmd.Flags().GetString("addr")
 			if err != nil {
 				return err
 			}
+			token, err := cmd.Flags().GetString("auth-token")
+			if err != nil {
+				return err
+			}
+			if token == "" {
+				token = strings.TrimSpace(os.Getenv(mcpAuthTokenEnvVar))
+			}
 
-			server := mcp.NewServer(&mcp.Implementation{
-				Name:    "nq-neptune-mcp",
-				Version: version,
-			}, nil)
+			switch transport {
+			case mcpTransportStdio:
+			case mcpTransportHTTP:
+				if token == "" {
+					return fmt.Errorf("--transport http requires --auth-token or %s", mcpAuthTokenEnvVar)
+				}
+			default:
+				return fmt.Errorf("invalid value for --transport: %s. Must be 'stdio' or 'http'", transport)
+			}
 
-			mcp.AddTool(
-				server,
-				&mcp.Tool{
-					Name:        "run_gremlin_query",
-					Description: "Run a Gremlin query against Neptune. Returns the JSON result from the database.",
-				},
-				func(ctx context.Context, req *mcp.CallToolRequest, args runGremlinArgs) (*mcp.CallToolResult, any, error) {
-					query := strings.TrimSpace(args.Query)
-					if query == "" {
-						return nil, nil, fmt.Errorf("query cannot be empty")
-					}
-
-					prettyJSON, _, execErr := appService.ExecuteQuery(query, "gremlin")
-					if execErr != nil {
-						return nil, nil, execErr
-					}
-
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: prettyJSON},
-						},
-					}, nil, nil
-				},
-			)
+			opts, err := mcpOptionsFromEnv()
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("max-result-bytes") {
+				if opts.MaxResultBytes, err = cmd.Flags().GetInt("max-result-bytes"); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("max-result-items") {
+				if opts.MaxResultItems, err = cmd.Flags().GetInt("max-result-items"); err != nil {
+					return err
+				}
+			}
+			if opts.MaxResultBytes <= 0 || opts.MaxResultItems <= 0 {
+				return fmt.Errorf("--max-result-bytes and --max-result-items must be positive")
+			}
 
-			mcp.AddTool(
-				server,
-				&mcp.Tool{
-					Name:        "get_graph_schema",
-					Description: "Returns the embedded graph schema (default). Set NQ_MCP_SCHEMA_SOURCE=dynamic to run live discovery (labels, properties, edge patterns, counts, enums).",
-				},
-				func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
-					prettyJSON, execErr := buildGraphSchema(ctx, appService)
-					if execErr != nil {
-						return nil, nil, execErr
-					}
-
-					return &mcp.CallToolResult{
-						Content: []mcp.Content{
-							&mcp.TextContent{Text: prettyJSON},
-						},
-					}, nil, nil
-				},
-			)
+			if dynamicSchemaCache.Refresh, err = cmd.Flags().GetBool("refresh-schema"); err != nil {
+				return err
+			}
+			if opts.StaticSchema, err = loadStaticSchema(); err != nil {
+				return err
+			}
+			opts.NoLint = noLint
+			if opts.Library, err = openLibrary(); err != nil {
+				return err
+			}
+
+			cacheInMemory = true
+			appService, err := newQueryService(cmd.Context(), true)
+			if err != nil {
+				return err
+			}
+
+			// Stdio clients read stdout only, so logging to stderr is safe
+			// with either transport.
+			logger := log.NewWithOptions(os.Stderr, log.Options{
+				ReportTimestamp: true,
+				TimeFormat:      time.RFC3339,
+			})
+			opts.Logger = logger
+
+			server, err := newMCPServer(appService, opts)
+			if err != nil {
+				return err
+			}
+
+			if transport == mcpTransportStdio {
+				return server.Run(cmd.Context(), &mcp.StdioTransport{})
+			}
+
+			server.AddReceivingMiddleware(mcpSessionLogging(logger))
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := serveMCPHTTP(ctx, newMCPHTTPHandler(server, token), addr, logger); err != nil {
+				logger.Error("MCP server stopped with error", "error", err)
+				return err
+			}
 
-			return server.Run(cmd.Context(), &mcp.StdioTransport{})
+			logger.Info("MCP server stopped")
+			return nil
 		},
 	}
 
+	cmd.Flags().String("transport", mcpTransportStdio, "MCP transport: 'stdio' or 'http' (streamable HTTP).")
+	cmd.Flags().String("addr", ":9090", "Address to bind the HTTP transport to.")
+	cmd.Flags().String("auth-token", "", "Bearer token HTTP clients must present (env "+mcpAuthTokenEnvVar+").")
+	cmd.Flags().Bool("refresh-schema", false, "Ignore the on-disk schema cache and rediscover the dynamic schema on first use.")
+	cmd.Flags().Int("max-result-bytes", defaultMCPMaxResultBytes, "Maximum bytes of query output per tool call before paging (env "+mcpMaxResultBytesEnvVar+").")
+	cmd.Flags().Int("max-result-items", defaultMCPMaxResultItems, "Maximum result items per tool call before paging (env "+mcpMaxResultItemsEnvVar+").")
+
 	return cmd
 }
+
+// newMCPServer registers the query and schema tools backed by appService.
+func newMCPServer(appService queryService, opts mcpOptions) (*mcp.Server, error) {
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    "nq-neptune-mcp",
+		Version: version,
+	}, nil)
+	pager := newResultPager(opts)
+
+	schemaDoc := opts.StaticSchema
+	if schemaDoc == nil {
+		var err error
+		if schemaDoc, err = schema.ParseStatic(); err != nil {
+			return nil, err
+		}
+	}
+	writeNote := readOnlyNote(appService)
+	checkQuery := func(query, language string) []lint.Warning {
+		if opts.NoLint {
+			return nil
+		}
+		return queryLinter(appService, schemaDoc).Check(query, language)
+	}
+
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name: "run_gremlin_query",
+			Description: "Run a Gremlin query against Neptune. Returns the JSON result from the database; large results are paged. " +
+				"Pass user-supplied values as bindings and reference them as $name instead of splicing them into the query. " +
+				explainNote + " " + writeNote + "\n\n" +
+				"Examples:\n" +
+				"  g.V().hasLabel('Study').limit(5).valueMap(true)\n" +
+				"  g.V().has('Study', 'name', $name).out('has_version').count()  with bindings {\"name\": \"ABC-123\"}",
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args runGremlinArgs) (*mcp.CallToolResult, any, error) {
+			return runQueryTool(ctx, appService, pager.forSession(req.Session), checkQuery, "gremlin", args.Query, args.Bindings, args.Explain, args.Profile)
+		},
+	)
+
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name: "run_cypher_query",
+			Description: "Run an openCypher query against Neptune. Returns the JSON result from the database; large results are paged. " +
+				"Pass user-supplied values as bindings and reference them as $name instead of splicing them into the query. " +
+				explainNote + " " + writeNote + "\n\n" +
+				"Examples:\n" +
+				"  MATCH (s:Study) RETURN s.name LIMIT 5\n" +
+				"  MATCH (s:Study {name: $name})-[:has_version]->(v) RETURN count(v)  with bindings {\"name\": \"ABC-123\"}",
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args runCypherArgs) (*mcp.CallToolResult, any, error) {
+			return runQueryTool(ctx, appService, pager.forSession(req.Session), checkQuery, "cypher", args.Query, args.Bindings, args.Explain, args.Profile)
+		},
+	)
+
+	runQuerySchema, err := jsonschema.For[runQueryArgs](nil)
+	if err != nil {
+		return nil, fmt.Errorf("build run_query schema: %w", err)
+	}
+	runQuerySchema.Properties["language"].Enum = []any{"gremlin", "cypher"}
+
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name: "run_query",
+			Description: "Run a Gremlin or openCypher query against Neptune, selected by language. Returns the JSON result from the database; large results are paged. " +
+				"Pass user-supplied values as bindings and reference them as $name. " +
+				explainNote + " " + writeNote + "\n\n" +
+				"Examples:\n" +
+				"  {\"language\": \"gremlin\", \"query\": \"g.V().hasLabel('Study').count()\"}\n" +
+				"  {\"language\": \"cypher\", \"query\": \"MATCH (s:Study) WHERE s.name = $name RETURN s\", \"bindings\": {\"name\": \"ABC-123\"}}",
+			InputSchema: runQuerySchema,
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args runQueryArgs) (*mcp.CallToolResult, any, error) {
+			return runQueryTool(ctx, appService, pager.forSession(req.Session), checkQuery, args.Language, args.Query, args.Bindings, args.Explain, args.Profile)
+		},
+	)
+
+	addResultPageTool(server, pager)
+	addSchemaResources(server, appService, schemaDoc)
+	addQueryPrompts(server, schemaDoc)
+	if opts.Library != nil {
+		logger := opts.Logger
+		if logger == nil {
+			logger = log.New(io.Discard)
+		}
+		if err := addLibraryTools(server, appService, pager, checkQuery, opts.Library, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_graph_schema",
+			Description: "Returns the static graph schema (default). Set NQ_MCP_SCHEMA_SOURCE=dynamic to run live discovery (labels, properties, edge patterns, counts, enums).",
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+			prettyJSON, execErr := buildGraphSchema(ctx, appService, schemaDoc, mcpProgress(ctx, req))
+			if execErr != nil {
+				return nil, nil, execErr
+			}
+
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: prettyJSON},
+				},
+			}, nil, nil
+		},
+	)
+
+	return server, nil
+}
+
+// mcpProgress forwards discovery progress to the client as progress
+// notifications when the request carries a progress token.
+func mcpProgress(ctx context.Context, req *mcp.CallToolRequest) func(done, total int) {
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	return func(done, total int) {
+		_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      float64(done),
+			Total:         float64(total),
+			Message:       fmt.Sprintf("schema discovery: %d of %d queries", done, total),
+		})
+	}
+}
+
+// runQueryTool validates, binds and executes a query on behalf of one of the
+// query tools. Errors are reported to the client as tool errors; names that
+// checkQuery does not find in the schema are noted after the result. With
+// explain or profile set, Neptune's plan is returned instead of the result.
+func runQueryTool(ctx context.Context, appService queryService, pager *resultPager, checkQuery func(query, language string) []lint.Warning, language, query string, bindings map[string]any, explain, profile bool) (*mcp.CallToolResult, any, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language != "gremlin" && language != "cypher" {
+		return nil, nil, fmt.Errorf("invalid language %q: must be 'gremlin' or 'cypher'", language)
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil, fmt.Errorf("query cannot be empty")
+	}
+
+	if explain && profile {
+		return nil, nil, fmt.Errorf("explain and profile cannot both be set")
+	}
+
+	query, err := params.Render(query, language, bindings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Lint first so that the warnings can also explain a failed query.
+	var warnings []string
+	for _, warning := range checkQuery(query, language) {
+		warnings = append(warnings, warning.Message)
+	}
+
+	var res *result.QueryResult
+	switch {
+	case explain:
+		res, err = explainQuery(ctx, appService, query, language, neptune.ModeExplain)
+	case profile:
+		res, err = explainQuery(ctx, appService, query, language, neptune.ModeProfile)
+	default:
+		res, err = appService.ExecuteQueryContext(ctx, query, language)
+	}
+	if err != nil {
+		if len(warnings) == 0 {
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: err.Error()},
+				&mcp.TextContent{Text: lintNote(warnings)},
+			},
+		}, nil, nil
+	}
+	res.Warnings = append(res.Warnings, warnings...)
+
+	toolResult, err := pager.firstPage(res.JSON())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res.Warnings) > 0 {
+		toolResult.Content = append(toolResult.Content, &mcp.TextContent{Text: lintNote(res.Warnings)})
+	}
+	if !res.CachedAt.IsZero() {
+		toolResult.Content = append(toolResult.Content, &mcp.TextContent{
+			Text: fmt.Sprintf("Served from the result cache; Neptune answered this query %s ago.", time.Since(res.CachedAt).Round(time.Second)),
+		})
+	}
+	return toolResult, nil, nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	mcpTransportStdio = "stdio"
+	mcpTransportHTTP  = "http"
+
+	mcpAuthTokenEnvVar     = "NQ_MCP_AUTH_TOKEN"
+	mcpHTTPPath            = "/mcp"
+	mcpHTTPReadTimeout     = 15 * time.Second
+	mcpHTTPShutdownTimeout = 5 * time.Second
+)
+
+// newMCPHTTPHandler serves server over the streamable HTTP transport at /mcp,
+// guarded by a static bearer token. /healthz stays unauthenticated for load
+// balancer probes.
+func newMCPHTTPHandler(server *mcp.Server, token string) http.Handler {
+	streamable := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, nil)
+
+	mux := http.NewServeMux()
+	mux.Handle(mcpHTTPPath, auth.RequireBearerToken(staticTokenVerifier(token), nil)(streamable))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// staticTokenVerifier accepts exactly one shared token. Both sides are hashed
+// first so the comparison is constant time regardless of length.
+func staticTokenVerifier(token string) auth.TokenVerifier {
+	want := sha256.Sum256([]byte(token))
+	return func(ctx context.Context, got string, r *http.Request) (*auth.TokenInfo, error) {
+		sum := sha256.Sum256([]byte(got))
+		if subtle.ConstantTimeCompare(sum[:], want[:]) != 1 {
+			return nil, auth.ErrInvalidToken
+		}
+		// The token does not expire; the SDK only requires an expiry to be set.
+		return &auth.TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+	}
+}
+
+// mcpSessionLogging logs every request received by the server with the ID of
+// the session it belongs to.
+func mcpSessionLogging(logger *log.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			start := time.Now()
+			result, err := next(ctx, method, req)
+
+			keyvals := []any{"session", req.GetSession().ID(), "method", method, "elapsed", time.Since(start).Round(time.Millisecond)}
+			if params, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok {
+				keyvals = append(keyvals, "tool", params.Name)
+			}
+			if callResult, ok := result.(*mcp.CallToolResult); ok && callResult.IsError {
+				keyvals = append(keyvals, "toolError", true)
+			}
+			if err != nil {
+				logger.Error("mcp request failed", append(keyvals, "error", err)...)
+			} else {
+				logger.Info("mcp request", keyvals...)
+			}
+			return result, err
+		}
+	}
+}
+
+// serveMCPHTTP runs handler on addr until ctx is done, then shuts down
+// gracefully, giving in-flight requests a few seconds to finish.
+func serveMCPHTTP(ctx context.Context, handler http.Handler, addr string, logger *log.Logger) error {
+	server := &http.Server{
+		Addr:        addr,
+		Handler:     handler,
+		ReadTimeout: mcpHTTPReadTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("MCP HTTP server starting", "addr", addr, "path", mcpHTTPPath)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), mcpHTTPShutdownTimeout)
+		defer cancel()
+		logger.Info("MCP HTTP server shutting down")
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("shutdown MCP HTTP server: %w", err)
+		}
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
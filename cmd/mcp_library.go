@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ankit-lilly/nqcli/internal/library"
+	"github.com/ankit-lilly/nqcli/internal/lint"
+	"github.com/ankit-lilly/nqcli/internal/params"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type listSavedQueriesArgs struct {
+	Tag string `json:"tag,omitempty" jsonschema:"Only list saved queries with this tag"`
+}
+
+type runSavedQueryArgs struct {
+	Name     string         `json:"name" jsonschema:"Name of the saved query, as returned by list_saved_queries"`
+	Bindings map[string]any `json:"bindings,omitempty" jsonschema:"Values for the query's $name placeholders; saved defaults fill in the rest"`
+	Explain  bool           `json:"explain,omitempty" jsonschema:"Return Neptune's query plan instead of running the query"`
+	Profile  bool           `json:"profile,omitempty" jsonschema:"Run the query and return Neptune's profile (plan with per-step timings and counts) instead of the result"`
+}
+
+// addLibraryTools exposes the saved query library: tools to list and run
+// saved queries, and one prompt per saved query. The tools read the library
+// on every call; prompts are registered for the queries saved when the
+// server starts.
+func addLibraryTools(server *mcp.Server, appService queryService, pager *resultPager, checkQuery func(query, language string) []lint.Warning, lib *library.Library, logger *log.Logger) error {
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name: "list_saved_queries",
+			Description: "List the team's saved queries with their description, language, tags, query text and default bindings. " +
+				"Prefer a saved query over writing a new one when one answers the question; run it with run_saved_query.",
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args listSavedQueriesArgs) (*mcp.CallToolResult, any, error) {
+			queries, problems, err := lib.List()
+			if err != nil {
+				return nil, nil, err
+			}
+			logLibraryProblems(logger, problems)
+			if tag := strings.TrimSpace(args.Tag); tag != "" {
+				queries = slices.DeleteFunc(queries, func(q library.Query) bool { return !q.HasTag(tag) })
+			}
+			data, err := json.MarshalIndent(queries, "", "  ")
+			if err != nil {
+				return nil, nil, err
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: string(data)}},
+			}, nil, nil
+		},
+	)
+
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name: "run_saved_query",
+			Description: "Run a saved query by name. Bindings fill its $name placeholders and override its saved defaults; large results are paged. " +
+				explainNote + " " + readOnlyNote(appService),
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args runSavedQueryArgs) (*mcp.CallToolResult, any, error) {
+			saved, err := lib.Get(strings.TrimSpace(args.Name))
+			if err != nil {
+				return nil, nil, err
+			}
+			query, err := saved.Render(args.Bindings)
+			if err != nil {
+				return nil, nil, err
+			}
+			return runQueryTool(ctx, appService, pager.forSession(req.Session), checkQuery, saved.Language, query, nil, args.Explain, args.Profile)
+		},
+	)
+
+	queries, problems, err := lib.List()
+	if err != nil {
+		return err
+	}
+	logLibraryProblems(logger, problems)
+	for _, q := range queries {
+		addSavedQueryPrompt(server, q)
+	}
+	return nil
+}
+
+// logLibraryProblems warns about saved query files the library skipped.
+func logLibraryProblems(logger *log.Logger, problems []error) {
+	for _, problem := range problems {
+		logger.Warn("skipping saved query", "error", problem)
+	}
+}
+
+// addSavedQueryPrompt registers a prompt named saved-NAME that asks the model
+// to run q, with one argument per placeholder. Placeholders with a saved
+// default are optional.
+func addSavedQueryPrompt(server *mcp.Server, q library.Query) {
+	names := params.Names(q.Query)
+	arguments := make([]*mcp.PromptArgument, 0, len(names))
+	for _, name := range names {
+		argument := &mcp.PromptArgument{Name: name, Required: true}
+		if value, ok := q.Params[name]; ok {
+			argument.Required = false
+			argument.Description = fmt.Sprintf("Defaults to %v.", value)
+		}
+		arguments = append(arguments, argument)
+	}
+
+	description := q.Description
+	if description == "" {
+		description = "Run the saved query " + q.Name + "."
+	}
+
+	server.AddPrompt(
+		&mcp.Prompt{
+			Name:        "saved-" + q.Name,
+			Title:       q.Name,
+			Description: description,
+			Arguments:   arguments,
+		},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			var assignments []string
+			for _, name := range names {
+				value := strings.TrimSpace(req.Params.Arguments[name])
+				if value == "" {
+					if _, ok := q.Params[name]; !ok {
+						return nil, fmt.Errorf("argument %q is required", name)
+					}
+					continue
+				}
+				assignments = append(assignments, name+"="+value)
+			}
+			bindings, err := params.ParseAssignments(assignments)
+			if err != nil {
+				return nil, err
+			}
+			bindingsJSON, err := json.Marshal(bindings)
+			if err != nil {
+				return nil, err
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Run the saved query %q with run_saved_query and bindings %s, then summarise the result.\n\n", q.Name, bindingsJSON)
+			if q.Description != "" {
+				fmt.Fprintf(&b, "What it answers: %s\n\n", q.Description)
+			}
+			fmt.Fprintf(&b, "The %s query:\n\n%s\n", q.Language, q.Query)
+
+			return &mcp.GetPromptResult{
+				Description: description,
+				Messages: []*mcp.PromptMessage{
+					{Role: "user", Content: &mcp.TextContent{Text: b.String()}},
+				},
+			}, nil
+		},
+	)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ankit-lilly/nqcli/internal/params"
+	"github.com/ankit-lilly/nqcli/internal/schema"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	schemaStaticURI    = "schema://static"
+	schemaDynamicURI   = "schema://dynamic"
+	schemaLabelURIBase = "schema://label/"
+	jsonMIMEType       = "application/json"
+)
+
+func jsonResource(uri, text string) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: jsonMIMEType, Text: text},
+		},
+	}
+}
+
+// addSchemaResources publishes the graph schema as resources so clients can
+// attach it to the model's context without a tool call.
+func addSchemaResources(server *mcp.Server, appService queryService, doc *schema.StaticDoc) {
+	server.AddResource(
+		&mcp.Resource{
+			URI:         schemaStaticURI,
+			Name:        "graph-schema-static",
+			Title:       "Graph schema (static)",
+			Description: "The maintained clinical-trials graph schema: labels, edges, property keys and query conventions.",
+			MIMEType:    jsonMIMEType,
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return jsonResource(schemaStaticURI, doc.JSON()), nil
+		},
+	)
+
+	server.AddResource(
+		&mcp.Resource{
+			URI:         schemaDynamicURI,
+			Name:        "graph-schema-dynamic",
+			Title:       "Graph schema (live discovery)",
+			Description: "Schema discovered from the live graph: labels, properties, edge patterns, counts and low-cardinality enums. Slow on large graphs.",
+			MIMEType:    jsonMIMEType,
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			graph, err := discoverSchema(ctx, appService, nil)
+			if err != nil {
+				return nil, err
+			}
+			payload, err := json.MarshalIndent(graph, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return jsonResource(schemaDynamicURI, string(payload)), nil
+		},
+	)
+
+	server.AddResourceTemplate(
+		&mcp.ResourceTemplate{
+			URITemplate: schemaLabelURIBase + "{name}",
+			Name:        "graph-schema-label",
+			Title:       "Graph schema for one vertex label",
+			Description: "Property keys, instance types and incoming/outgoing edges for a single vertex label from the static schema.",
+			MIMEType:    jsonMIMEType,
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			uri := req.Params.URI
+			label, ok := strings.CutPrefix(uri, schemaLabelURIBase)
+			if !ok {
+				return nil, mcp.ResourceNotFoundError(uri)
+			}
+			labelSchema, found := doc.Label(label)
+			if !found {
+				return nil, mcp.ResourceNotFoundError(uri)
+			}
+			payload, err := json.MarshalIndent(labelSchema, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return jsonResource(uri, string(payload)), nil
+		},
+	)
+}
+
+// addQueryPrompts registers prompts that seed a conversation with the schema
+// and the query conventions from its notes.
+func addQueryPrompts(server *mcp.Server, doc *schema.StaticDoc) {
+	server.AddPrompt(
+		&mcp.Prompt{
+			Name:        "explore-study",
+			Title:       "Explore a study",
+			Description: "Walk a study's versions, designs, identifiers and related data using the graph schema.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "study", Description: "The Study name to explore, for example a protocol identifier.", Required: true},
+			},
+		},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			study := strings.TrimSpace(req.Params.Arguments["study"])
+			if study == "" {
+				return nil, fmt.Errorf("argument %q is required", "study")
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Explore the study named %q in the Neptune graph and summarise what it contains.\n\n", study)
+			fmt.Fprintf(&b, "Start from g.V().has(%s, 'name', $study) and pass {\"study\": %q} as bindings to run_gremlin_query. ", params.QuoteGremlin(doc.RootLabel), study)
+			b.WriteString("Follow the edges in the schema below one hop at a time: versions, titles, identifiers, designs, then anything else that looks relevant. ")
+			b.WriteString("Prefer count(), valueMap() and limit() over returning whole subgraphs.\n\n")
+			writeSchemaContext(&b, doc)
+
+			return &mcp.GetPromptResult{
+				Description: "Explore study " + study,
+				Messages: []*mcp.PromptMessage{
+					{Role: "user", Content: &mcp.TextContent{Text: b.String()}},
+				},
+			}, nil
+		},
+	)
+
+	server.AddPrompt(
+		&mcp.Prompt{
+			Name:        "write-gremlin-for-question",
+			Title:       "Write Gremlin for a question",
+			Description: "Turn a natural-language question into a Gremlin query that follows the graph schema and its conventions.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "question", Description: "The question to answer from the graph.", Required: true},
+			},
+		},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			question := strings.TrimSpace(req.Params.Arguments["question"])
+			if question == "" {
+				return nil, fmt.Errorf("argument %q is required", "question")
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "Write a Gremlin query that answers this question:\n\n%s\n\n", question)
+			b.WriteString("Use only the labels, edges and property keys in the schema below. ")
+			b.WriteString("Reference literal values as $name placeholders and supply them as bindings rather than quoting them into the query. ")
+			b.WriteString("Keep the query read-only and bounded with limit() or count(). ")
+			b.WriteString("Explain the traversal briefly, then run it with run_gremlin_query.\n\n")
+			writeSchemaContext(&b, doc)
+
+			return &mcp.GetPromptResult{
+				Description: "Gremlin for: " + question,
+				Messages: []*mcp.PromptMessage{
+					{Role: "user", Content: &mcp.TextContent{Text: b.String()}},
+				},
+			}, nil
+		},
+	)
+}
+
+func writeSchemaContext(b *strings.Builder, doc *schema.StaticDoc) {
+	b.WriteString("Query conventions:\n")
+	for _, note := range doc.Notes {
+		fmt.Fprintf(b, "- %s\n", note)
+	}
+	fmt.Fprintf(b, "- The root vertex label is %s.\n\n", doc.RootLabel)
+	fmt.Fprintf(b, "Graph schema (also available as the %s resource):\n", schemaStaticURI)
+	b.WriteString(doc.JSON())
+	b.WriteString("\n")
+}
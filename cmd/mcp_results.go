@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ankit-lilly/nqcli/internal/library"
+	"github.com/ankit-lilly/nqcli/internal/schema"
+
+	"github.com/charmbracelet/log"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultMCPMaxResultBytes = 32 * 1024
+	defaultMCPMaxResultItems = 100
+	mcpMaxResultBytesEnvVar  = "NQ_MCP_MAX_RESULT_BYTES"
+	mcpMaxResultItemsEnvVar  = "NQ_MCP_MAX_RESULT_ITEMS"
+
+	// Stored results are dropped after resultStoreTTL or once their session
+	// has more than resultStoreCapacity newer results.
+	resultStoreTTL      = 15 * time.Minute
+	resultStoreCapacity = 32
+)
+
+// mcpOptions tunes the MCP server.
+type mcpOptions struct {
+	// MaxResultBytes bounds the text returned by a single tool call. At
+	// roughly four bytes per token the default keeps a page near 8k tokens.
+	MaxResultBytes int
+	// MaxResultItems bounds the number of list items per page.
+	MaxResultItems int
+	// StaticSchema is served by get_graph_schema, the schema resources and
+	// the prompts; nil selects the embedded schema.
+	StaticSchema *schema.StaticDoc
+	// NoLint turns off the schema warnings appended to query results.
+	NoLint bool
+	// Library is the saved query library served by the saved query tools
+	// and prompts; nil leaves them out.
+	Library *library.Library
+	// Logger receives warnings such as unreadable saved query files; nil
+	// discards them.
+	Logger *log.Logger
+}
+
+func defaultMCPOptions() mcpOptions {
+	return mcpOptions{
+		MaxResultBytes: defaultMCPMaxResultBytes,
+		MaxResultItems: defaultMCPMaxResultItems,
+	}
+}
+
+// mcpOptionsFromEnv applies NQ_MCP_MAX_RESULT_BYTES and
+// NQ_MCP_MAX_RESULT_ITEMS on top of the defaults.
+func mcpOptionsFromEnv() (mcpOptions, error) {
+	opts := defaultMCPOptions()
+	for _, setting := range []struct {
+		key    string
+		target *int
+	}{
+		{mcpMaxResultBytesEnvVar, &opts.MaxResultBytes},
+		{mcpMaxResultItemsEnvVar, &opts.MaxResultItems},
+	} {
+		value := strings.TrimSpace(os.Getenv(setting.key))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return opts, fmt.Errorf("invalid %s %q: must be a positive integer", setting.key, value)
+		}
+		*setting.target = parsed
+	}
+	return opts, nil
+}
+
+type resultPageArgs struct {
+	Cursor string `json:"cursor" jsonschema:"The cursor from a truncated result's continuation note"`
+}
+
+// storedResult is a complete query result kept for paging. Lists are paged
+// by item; anything else is paged by bytes of its JSON text.
+type storedResult struct {
+	items   []json.RawMessage
+	text    string
+	isList  bool
+	created time.Time
+}
+
+func (r *storedResult) total() int {
+	if r.isList {
+		return len(r.items)
+	}
+	return len(r.text)
+}
+
+// resultStore keeps truncated results in memory so clients can fetch the
+// remaining pages. Results belong to the MCP session that produced them:
+// each session has its own capacity, cannot read another session's handles,
+// and loses its results when it closes.
+type resultStore struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*sessionResults
+	now      func() time.Time
+}
+
+// sessionResults holds one session's results, keyed by a random handle, in
+// the order they were stored.
+type sessionResults struct {
+	results map[string]*storedResult
+	order   []string
+}
+
+func newResultStore() *resultStore {
+	return &resultStore{
+		sessions: make(map[*mcp.ServerSession]*sessionResults),
+		now:      time.Now,
+	}
+}
+
+func (s *resultStore) put(session *mcp.ServerSession, result *storedResult) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate result handle: %w", err)
+	}
+	handle := hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.sessions[session]
+	if !ok {
+		stored = &sessionResults{results: make(map[string]*storedResult)}
+		s.sessions[session] = stored
+		if session != nil {
+			go func() {
+				_ = session.Wait()
+				s.drop(session)
+			}()
+		}
+	}
+	s.evictLocked(stored)
+	result.created = s.now()
+	stored.results[handle] = result
+	stored.order = append(stored.order, handle)
+	for len(stored.order) > resultStoreCapacity {
+		delete(stored.results, stored.order[0])
+		stored.order = stored.order[1:]
+	}
+	return handle, nil
+}
+
+func (s *resultStore) get(session *mcp.ServerSession, handle string) (*storedResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.sessions[session]
+	if !ok {
+		return nil, false
+	}
+	s.evictLocked(stored)
+	result, ok := stored.results[handle]
+	return result, ok
+}
+
+// drop forgets every result of session.
+func (s *resultStore) drop(session *mcp.ServerSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, session)
+}
+
+func (s *resultStore) evictLocked(stored *sessionResults) {
+	cutoff := s.now().Add(-resultStoreTTL)
+	kept := stored.order[:0]
+	for _, handle := range stored.order {
+		if stored.results[handle].created.Before(cutoff) {
+			delete(stored.results, handle)
+			continue
+		}
+		kept = append(kept, handle)
+	}
+	stored.order = kept
+}
+
+// resultPager turns query output into size-limited tool results. Results
+// are stored for session, nil outside an MCP session.
+type resultPager struct {
+	opts    mcpOptions
+	store   *resultStore
+	session *mcp.ServerSession
+}
+
+func newResultPager(opts mcpOptions) *resultPager {
+	return &resultPager{opts: opts, store: newResultStore()}
+}
+
+// forSession returns a pager sharing p's store that keeps results for
+// session.
+func (p *resultPager) forSession(session *mcp.ServerSession) *resultPager {
+	bound := *p
+	bound.session = session
+	return &bound
+}
+
+// firstPage returns output unchanged when it fits the limits. Otherwise the
+// full result is stored and the first page is returned with a continuation
+// note naming the cursor for the next page.
+func (p *resultPager) firstPage(output string) (*mcp.CallToolResult, error) {
+	result := &storedResult{text: output}
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(output), &items); err == nil {
+		result.items = items
+		result.isList = true
+	}
+
+	if len(output) <= p.opts.MaxResultBytes && (!result.isList || len(items) <= p.opts.MaxResultItems) {
+		return textResult(output), nil
+	}
+
+	handle, err := p.store.put(p.session, result)
+	if err != nil {
+		return nil, err
+	}
+	return p.page(handle, result, 0), nil
+}
+
+// nextPage resumes a stored result at cursor.
+func (p *resultPager) nextPage(cursor string) (*mcp.CallToolResult, error) {
+	handle, offsetText, ok := strings.Cut(strings.TrimSpace(cursor), ":")
+	offset, err := strconv.Atoi(offsetText)
+	if !ok || err != nil || offset < 0 {
+		return nil, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	result, found := p.store.get(p.session, handle)
+	if !found {
+		return nil, fmt.Errorf("result for cursor %q has expired; run the query again", cursor)
+	}
+	if offset >= result.total() {
+		return nil, fmt.Errorf("cursor %q is past the end of the result", cursor)
+	}
+	return p.page(handle, result, offset), nil
+}
+
+func (p *resultPager) page(handle string, result *storedResult, offset int) *mcp.CallToolResult {
+	var (
+		text string
+		next int
+	)
+	if result.isList {
+		text, next = p.listPage(result.items, offset)
+	} else {
+		text, next = p.textPage(result.text, offset)
+	}
+
+	remaining := result.total() - next
+	if remaining == 0 {
+		return textResult(text)
+	}
+
+	var note string
+	if result.isList {
+		note = fmt.Sprintf("Result truncated: showing items %d-%d of %d, %d more items.", offset+1, next, result.total(), remaining)
+	} else {
+		note = fmt.Sprintf("Result truncated: showing bytes %d-%d of %d, %d more bytes.", offset+1, next, result.total(), remaining)
+	}
+	note += fmt.Sprintf(" Call get_result_page with cursor %q for the next page, or refine the query (limit, range, project) to return less.", fmt.Sprintf("%s:%d", handle, next))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+			&mcp.TextContent{Text: note},
+		},
+	}
+}
+
+// listPage renders items from offset as an indented JSON array, stopping at
+// the item or byte limit. At least one item is always included; an item
+// that alone exceeds the byte limit is cut short.
+func (p *resultPager) listPage(items []json.RawMessage, offset int) (string, int) {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	next := offset
+	for next < len(items) && next-offset < p.opts.MaxResultItems {
+		var item bytes.Buffer
+		if err := json.Indent(&item, items[next], "  ", "  "); err != nil {
+			item.Reset()
+			item.Write(items[next])
+		}
+		separator := "\n  "
+		if next > offset {
+			separator = ",\n  "
+		}
+
+		if next > offset && buf.Len()+len(separator)+item.Len()+2 > p.opts.MaxResultBytes {
+			break
+		}
+		buf.WriteString(separator)
+		if next == offset && item.Len() > p.opts.MaxResultBytes {
+			buf.WriteString(truncateUTF8(item.String(), p.opts.MaxResultBytes))
+			buf.WriteString(" ... (item truncated)")
+		} else {
+			buf.Write(item.Bytes())
+		}
+		next++
+	}
+	buf.WriteString("\n]")
+	return buf.String(), next
+}
+
+func (p *resultPager) textPage(text string, offset int) (string, int) {
+	chunk := truncateUTF8(text[offset:], p.opts.MaxResultBytes)
+	if chunk == "" {
+		// A single rune wider than the limit; emit it anyway to make progress.
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		chunk = text[offset : offset+size]
+	}
+	return chunk, offset + len(chunk)
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+}
+
+func addResultPageTool(server *mcp.Server, pager *resultPager) {
+	mcp.AddTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_result_page",
+			Description: "Fetch the next page of a truncated query result. Pass the cursor from the continuation note of the previous page. Results are kept for 15 minutes.",
+		},
+		func(ctx context.Context, req *mcp.CallToolRequest, args resultPageArgs) (*mcp.CallToolResult, any, error) {
+			result, err := pager.forSession(req.Session).nextPage(args.Cursor)
+			if err != nil {
+				return nil, nil, err
+			}
+			return result, nil, nil
+		},
+	)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func resultTexts(t *testing.T, result *mcp.CallToolResult) (string, string) {
+	t.Helper()
+	var texts []string
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok {
+			t.Fatalf("unexpected content %T", content)
+		}
+		texts = append(texts, text.Text)
+	}
+	switch len(texts) {
+	case 1:
+		return texts[0], ""
+	case 2:
+		return texts[0], texts[1]
+	}
+	t.Fatalf("expected one or two text contents, got %d", len(texts))
+	return "", ""
+}
+
+func cursorFromNote(t *testing.T, note string) string {
+	t.Helper()
+	_, rest, ok := strings.Cut(note, `cursor "`)
+	if !ok {
+		t.Fatalf("no cursor in note %q", note)
+	}
+	cursor, _, _ := strings.Cut(rest, `"`)
+	return cursor
+}
+
+func TestResultPagerReturnsSmallResultsUnchanged(t *testing.T) {
+	pager := newResultPager(defaultMCPOptions())
+
+	result, err := pager.firstPage(`[1, 2, 3]`)
+	if err != nil {
+		t.Fatalf("firstPage: %v", err)
+	}
+	text, note := resultTexts(t, result)
+	if text != `[1, 2, 3]` || note != "" {
+		t.Fatalf("expected untouched output, got %q / %q", text, note)
+	}
+}
+
+func TestResultPagerPagesListsByItems(t *testing.T) {
+	pager := newResultPager(mcpOptions{MaxResultBytes: 1 << 20, MaxResultItems: 2})
+
+	items := make([]string, 5)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id": %d}`, i)
+	}
+	output := "[" + strings.Join(items, ",") + "]"
+
+	var seen []int
+	result, err := pager.firstPage(output)
+	for page := 0; ; page++ {
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		text, note := resultTexts(t, result)
+
+		var decoded []struct{ ID int }
+		if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+			t.Fatalf("page %d is not a JSON array: %v\n%s", page, err, text)
+		}
+		for _, item := range decoded {
+			seen = append(seen, item.ID)
+		}
+		if note == "" {
+			break
+		}
+		if page == 0 && !strings.Contains(note, "3 more items") {
+			t.Fatalf("expected remaining count in note, got %q", note)
+		}
+		result, err = pager.nextPage(cursorFromNote(t, note))
+	}
+
+	if fmt.Sprint(seen) != "[0 1 2 3 4]" {
+		t.Fatalf("expected every item exactly once, got %v", seen)
+	}
+}
+
+func TestResultPagerPagesTextByBytes(t *testing.T) {
+	pager := newResultPager(mcpOptions{MaxResultBytes: 10, MaxResultItems: 100})
+
+	output := `{"name": "a long single object result"}`
+	var rebuilt strings.Builder
+	result, err := pager.firstPage(output)
+	for {
+		if err != nil {
+			t.Fatalf("page: %v", err)
+		}
+		text, note := resultTexts(t, result)
+		if len(text) > 10 {
+			t.Fatalf("page exceeds byte limit: %q", text)
+		}
+		rebuilt.WriteString(text)
+		if note == "" {
+			break
+		}
+		result, err = pager.nextPage(cursorFromNote(t, note))
+	}
+
+	if rebuilt.String() != output {
+		t.Fatalf("expected pages to reassemble the output, got %q", rebuilt.String())
+	}
+}
+
+func TestResultPagerRejectsExpiredCursor(t *testing.T) {
+	pager := newResultPager(mcpOptions{MaxResultBytes: 1 << 20, MaxResultItems: 1})
+	now := time.Now()
+	pager.store.now = func() time.Time { return now }
+
+	result, err := pager.firstPage(`[1, 2]`)
+	if err != nil {
+		t.Fatalf("firstPage: %v", err)
+	}
+	_, note := resultTexts(t, result)
+	cursor := cursorFromNote(t, note)
+
+	now = now.Add(resultStoreTTL + time.Minute)
+	if _, err := pager.nextPage(cursor); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("expected expired cursor error, got %v", err)
+	}
+	if _, err := pager.nextPage("bogus"); err == nil {
+		t.Fatalf("expected invalid cursor error")
+	}
+}
+
+func TestResultPagerKeepsResultsPerSession(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	connect := func() *mcp.ServerSession {
+		serverTransport, _ := mcp.NewInMemoryTransports()
+		session, err := server.Connect(context.Background(), serverTransport, nil)
+		if err != nil {
+			t.Fatalf("server connect: %v", err)
+		}
+		t.Cleanup(func() { session.Close() })
+		return session
+	}
+	first, second := connect(), connect()
+	pager := newResultPager(mcpOptions{MaxResultBytes: 1 << 20, MaxResultItems: 1})
+
+	result, err := pager.forSession(first).firstPage(`[1, 2]`)
+	if err != nil {
+		t.Fatalf("firstPage: %v", err)
+	}
+	_, note := resultTexts(t, result)
+	cursor := cursorFromNote(t, note)
+
+	if _, err := pager.forSession(second).nextPage(cursor); err == nil {
+		t.Fatalf("expected another session not to read the result")
+	}
+	for range resultStoreCapacity + 1 {
+		if _, err := pager.forSession(second).firstPage(`[1, 2]`); err != nil {
+			t.Fatalf("firstPage: %v", err)
+		}
+	}
+	if _, err := pager.forSession(first).nextPage(cursor); err != nil {
+		t.Fatalf("expected another session's results not to evict this one's, got %v", err)
+	}
+
+	first.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pager.store.mu.Lock()
+		_, kept := pager.store.sessions[first]
+		pager.store.mu.Unlock()
+		if !kept {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a closed session's results to be dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
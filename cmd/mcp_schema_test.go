@@ -4,36 +4,47 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/ankit-lilly/nqcli/internal/schema"
 )
 
 type stubQueryService struct {
+	mu        sync.Mutex
 	execCalls int
 	execErr   error
 }
 
-func (s *stubQueryService) Execute(_ string, _ string) (string, string, error) {
-	return "", "", errors.New("not implemented")
+func (s *stubQueryService) ExecuteContext(_ context.Context, _ string, _ string) (*result.QueryResult, error) {
+	return nil, errors.New("not implemented")
 }
 
-func (s *stubQueryService) ExecuteQuery(_ string, _ string) (string, string, error) {
+func (s *stubQueryService) ExecuteQueryContext(_ context.Context, _ string, _ string) (*result.QueryResult, error) {
+	s.mu.Lock()
 	s.execCalls++
+	s.mu.Unlock()
 	if s.execErr != nil {
-		return "", "", s.execErr
+		return nil, s.execErr
 	}
-	return "[]", "", nil
+	return result.FromPayload("[]", result.Options{}), nil
 }
 
 func TestBuildGraphSchemaStaticDefault(t *testing.T) {
 	t.Setenv(schemaSourceEnvVar, "")
 
+	static, err := schema.ParseStatic()
+	if err != nil {
+		t.Fatalf("ParseStatic: %v", err)
+	}
 	service := &stubQueryService{}
-	got, err := buildGraphSchema(context.Background(), service)
+	got, err := buildGraphSchema(context.Background(), service, static, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	want := strings.TrimSpace(staticSchemaJSON)
+	want := schema.StaticJSON()
 	if strings.TrimSpace(got) != want {
 		t.Fatalf("expected static schema to be returned")
 	}
@@ -43,15 +54,19 @@ func TestBuildGraphSchemaStaticDefault(t *testing.T) {
 }
 
 func TestBuildGraphSchemaDynamicFallbackOnError(t *testing.T) {
-	t.Setenv(schemaSourceEnvVar, schemaSourceDynamic)
+	t.Setenv(schemaSourceEnvVar, string(schema.Dynamic))
 
+	static, err := schema.ParseStatic()
+	if err != nil {
+		t.Fatalf("ParseStatic: %v", err)
+	}
 	service := &stubQueryService{execErr: errors.New("boom")}
-	got, err := buildGraphSchema(context.Background(), service)
+	got, err := buildGraphSchema(context.Background(), service, static, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	want := strings.TrimSpace(staticSchemaJSON)
+	want := schema.StaticJSON()
 	if strings.TrimSpace(got) != want {
 		t.Fatalf("expected static schema fallback to be returned")
 	}
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/library"
+	"github.com/ankit-lilly/nqcli/internal/schema"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func connectTestMCP(t *testing.T, service queryService) *mcp.ClientSession {
+	t.Helper()
+	return connectTestMCPWithOptions(t, service, defaultMCPOptions())
+}
+
+func connectTestMCPWithOptions(t *testing.T, service queryService, opts mcpOptions) *mcp.ClientSession {
+	t.Helper()
+
+	server, err := newMCPServer(service, opts)
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestMCPRunCypherQueryRendersBindings(t *testing.T) {
+	spy := &spyQueryService{}
+	session := connectTestMCP(t, spy)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name: "run_cypher_query",
+		Arguments: map[string]any{
+			"query":    "MATCH (s:Study {name: $name}) RETURN s LIMIT $limit",
+			"bindings": map[string]any{"name": "ABC-123", "limit": 5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+	if spy.lastQueryType != "cypher" {
+		t.Fatalf("expected cypher query type, got %q", spy.lastQueryType)
+	}
+	if want := "MATCH (s:Study {name: 'ABC-123'}) RETURN s LIMIT 5"; spy.lastQuery != want {
+		t.Fatalf("expected query %q, got %q", want, spy.lastQuery)
+	}
+}
+
+func TestMCPRunQueryExplain(t *testing.T) {
+	spy := &spyQueryService{}
+	session := connectTestMCP(t, spy)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_query",
+		Arguments: map[string]any{"language": "gremlin", "query": "g.V().count()", "explain": true},
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+	if spy.explainCalls != 1 || spy.executeQueryCalls != 0 {
+		t.Fatalf("expected only ExplainQueryContext to be called, got %d explain and %d execute calls", spy.explainCalls, spy.executeQueryCalls)
+	}
+	if spy.lastMode != neptune.ModeExplain {
+		t.Fatalf("expected mode %q, got %q", neptune.ModeExplain, spy.lastMode)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "Query plan" {
+		t.Fatalf("expected the plan as the result, got %q", text)
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_query",
+		Arguments: map[string]any{"language": "gremlin", "query": "g.V().count()", "explain": true, "profile": true},
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("expected setting both explain and profile to be a tool error")
+	}
+}
+
+func TestMCPRunQueryValidatesLanguage(t *testing.T) {
+	spy := &spyQueryService{}
+	session := connectTestMCP(t, spy)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_query",
+		Arguments: map[string]any{"language": "gremlin", "query": "g.V().count()"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("expected gremlin query to succeed, got err=%v result=%+v", err, result)
+	}
+	if spy.lastQueryType != "gremlin" || spy.executeQueryCalls != 1 {
+		t.Fatalf("expected one gremlin query, got %d calls of type %q", spy.executeQueryCalls, spy.lastQueryType)
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_query",
+		Arguments: map[string]any{"language": "sparql", "query": "SELECT * WHERE { ?s ?p ?o }"},
+	})
+	if err == nil && !result.IsError {
+		t.Fatalf("expected unsupported language to be rejected")
+	}
+	if spy.executeQueryCalls != 1 {
+		t.Fatalf("expected rejected query not to run, got %d calls", spy.executeQueryCalls)
+	}
+}
+
+func TestMCPRunGremlinQueryAddsSchemaWarnings(t *testing.T) {
+	session := connectTestMCP(t, &spyQueryService{})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_gremlin_query",
+		Arguments: map[string]any{"query": "g.V().hasLabel('Study').out('has_versions').count()"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("expected query to run, got err=%v result=%+v", err, result)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("expected the result and a warning note, got %+v", result.Content)
+	}
+	note := result.Content[1].(*mcp.TextContent).Text
+	if !strings.Contains(note, `unknown edge label "has_versions"; did you mean "has_version"?`) {
+		t.Fatalf("unexpected note %q", note)
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_gremlin_query",
+		Arguments: map[string]any{"query": "g.V().hasLabel('Study').out('has_version').count()"},
+	})
+	if err != nil || len(result.Content) != 1 {
+		t.Fatalf("expected no note for a valid query, got err=%v result=%+v", err, result)
+	}
+}
+
+func TestMCPRunGremlinQueryAddsSchemaWarningsToErrors(t *testing.T) {
+	session := connectTestMCP(t, &stubQueryService{execErr: errors.New("boom")})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_gremlin_query",
+		Arguments: map[string]any{"query": "g.V().hasLabel('Study').out('has_versions').count()"},
+	})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected a tool error, got err=%v result=%+v", err, result)
+	}
+	if len(result.Content) != 2 || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "boom") {
+		t.Fatalf("expected the error and a warning note, got %+v", result.Content)
+	}
+	if note := result.Content[1].(*mcp.TextContent).Text; !strings.Contains(note, `unknown edge label "has_versions"`) {
+		t.Fatalf("unexpected note %q", note)
+	}
+}
+
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestMCPHTTPRequiresBearerToken(t *testing.T) {
+	spy := &spyQueryService{}
+	server, err := newMCPServer(spy, defaultMCPOptions())
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	httpServer := httptest.NewServer(newMCPHTTPHandler(server, "s3cret"))
+	defer httpServer.Close()
+
+	resp, err := http.Post(httpServer.URL+mcpHTTPPath, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without a token, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:             httpServer.URL + mcpHTTPPath,
+		HTTPClient:           &http.Client{Transport: bearerTransport{token: "s3cret"}},
+		DisableStandaloneSSE: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "run_gremlin_query",
+		Arguments: map[string]any{"query": "g.V().count()"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("expected tool call to succeed, got err=%v result=%+v", err, result)
+	}
+	if spy.lastQuery != "g.V().count()" {
+		t.Fatalf("expected query to reach the service, got %q", spy.lastQuery)
+	}
+}
+
+func TestMCPSchemaResourcesAndPrompts(t *testing.T) {
+	session := connectTestMCP(t, &spyQueryService{})
+	ctx := context.Background()
+
+	static, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: schemaStaticURI})
+	if err != nil {
+		t.Fatalf("read static schema: %v", err)
+	}
+	if len(static.Contents) != 1 || static.Contents[0].Text != schema.StaticJSON() {
+		t.Fatalf("expected embedded schema from %s", schemaStaticURI)
+	}
+
+	label, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "schema://label/StudyVersion"})
+	if err != nil {
+		t.Fatalf("read label schema: %v", err)
+	}
+	var labelSchema schema.StaticLabel
+	if err := json.Unmarshal([]byte(label.Contents[0].Text), &labelSchema); err != nil {
+		t.Fatalf("decode label schema: %v", err)
+	}
+	if len(labelSchema.Incoming) != 1 || labelSchema.Incoming[0].From != "Study" || labelSchema.Incoming[0].EdgeLabel != "has_version" {
+		t.Fatalf("expected Study -has_version-> StudyVersion, got %+v", labelSchema.Incoming)
+	}
+	if !slices.Contains(labelSchema.Properties, "versionIdentifier") {
+		t.Fatalf("expected StudyVersion properties, got %v", labelSchema.Properties)
+	}
+
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "schema://label/Nope"}); err == nil {
+		t.Fatalf("expected unknown label to be rejected")
+	}
+
+	prompt, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "write-gremlin-for-question",
+		Arguments: map[string]string{"question": "How many studies are there?"},
+	})
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	text := prompt.Messages[0].Content.(*mcp.TextContent).Text
+	if !strings.Contains(text, "How many studies are there?") || !strings.Contains(text, "Traversal source is g.") {
+		t.Fatalf("expected question and schema notes in prompt, got %q", text)
+	}
+}
+
+func TestMCPSavedQueries(t *testing.T) {
+	lib := library.New(t.TempDir(), "")
+	if _, err := lib.Save(library.Query{
+		Name:        "study-versions",
+		Description: "Versions of a study",
+		Language:    "gremlin",
+		Query:       "g.V().has('Study', 'name', $study).out('has_version').limit($limit)",
+		Params:      map[string]any{"limit": 10},
+	}, false); err != nil {
+		t.Fatalf("save query: %v", err)
+	}
+
+	spy := &spyQueryService{}
+	opts := defaultMCPOptions()
+	opts.Library = lib
+	session := connectTestMCPWithOptions(t, spy, opts)
+	ctx := context.Background()
+
+	listed, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "list_saved_queries", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool list_saved_queries: %v", err)
+	}
+	if text := listed.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, `"name": "study-versions"`) {
+		t.Fatalf("expected the saved query in the list, got %s", text)
+	}
+
+	ran, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "run_saved_query",
+		Arguments: map[string]any{"name": "study-versions", "bindings": map[string]any{"study": "LY01"}},
+	})
+	if err != nil {
+		t.Fatalf("CallTool run_saved_query: %v", err)
+	}
+	if ran.IsError {
+		t.Fatalf("unexpected tool error: %+v", ran.Content)
+	}
+	if want := "g.V().has('Study', 'name', 'LY01').out('has_version').limit(10)"; spy.lastQuery != want {
+		t.Fatalf("expected query %q, got %q", want, spy.lastQuery)
+	}
+
+	prompt, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      "saved-study-versions",
+		Arguments: map[string]string{"study": "LY01"},
+	})
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	if text := prompt.Messages[0].Content.(*mcp.TextContent).Text; !strings.Contains(text, `{"study":"LY01"}`) {
+		t.Fatalf("expected the bindings in the prompt, got %q", text)
+	}
+	if _, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "saved-study-versions"}); err == nil {
+		t.Fatalf("expected a missing required argument to be rejected")
+	}
+}
+
+type readOnlyQueryService struct {
+	spyQueryService
+	readOnly bool
+}
+
+func (s *readOnlyQueryService) ReadOnly() bool { return s.readOnly }
+
+func TestMCPToolDescriptionsReportReadOnlyMode(t *testing.T) {
+	for _, readOnly := range []bool{true, false} {
+		session := connectTestMCP(t, &readOnlyQueryService{readOnly: readOnly})
+		tools, err := session.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListTools: %v", err)
+		}
+		want := readOnlyNote(&readOnlyQueryService{readOnly: readOnly})
+		for _, tool := range tools.Tools {
+			if strings.HasPrefix(tool.Name, "run_") && !strings.Contains(tool.Description, want) {
+				t.Fatalf("expected %s to say %q, got %q", tool.Name, want, tool.Description)
+			}
+		}
+	}
+	if readOnlyNote(&readOnlyQueryService{readOnly: true}) == readOnlyNote(&readOnlyQueryService{}) {
+		t.Fatalf("expected the note to depend on the read-only setting")
+	}
+}
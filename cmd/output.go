@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/ankit-lilly/nqcli/internal/format"
+	"github.com/ankit-lilly/nqcli/internal/result"
+
+	"github.com/mattn/go-isatty"
+)
+
+// writeResult prints a query result in the requested format, styling tables
+// when w is a terminal.
+func writeResult(w io.Writer, res *result.QueryResult, f format.Format) error {
+	return res.Write(w, f, format.Options{Styled: isTerminal(w)})
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ankit-lilly/nqcli/internal/app"
+	"github.com/ankit-lilly/nqcli/internal/config"
+	"github.com/ankit-lilly/nqcli/internal/format"
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/library"
+	"github.com/ankit-lilly/nqcli/internal/params"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newQueryCommand())
+}
+
+// openLibrary returns the saved query library for the selected config file.
+func openLibrary() (*library.Library, error) {
+	dir, shared, err := config.QueryDirs(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return library.New(dir, shared), nil
+}
+
+func newQueryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Save, list and run named queries from the query library.",
+		Long: `Save, list and run named queries from the query library.
+
+Saved queries are YAML files in the "queries" directory next to the config
+file. A shared team directory set with shared_queries in the config file (or
+$NQ_SHARED_QUERIES) is read as well; a personal query hides a shared one of
+the same name. Saved queries may reference $name placeholders, with default
+values saved alongside the query and overridden with --param when run.`,
+	}
+	cmd.AddCommand(newQuerySaveCommand(), newQueryListCommand(), newQueryShowCommand(), newQueryRunCommand())
+	return cmd
+}
+
+func newQuerySaveCommand() *cobra.Command {
+	var (
+		description string
+		tags        []string
+		defaults    []string
+		force       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "save NAME [query_file|query]",
+		Short: "Save a query to the library.",
+		Long: `Save a query to the library.
+
+The query is read from the file or inline text given, or from stdin.
+
+Examples:
+  nq query save study-versions --tag study --param limit=10 \
+    "g.V().has('Study', 'name', $study).out('has_version').limit($limit)"
+  nq query save --type cypher studies studies.cypher`,
+		Args:          cobra.RangeArgs(1, 2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queryType, err := cmd.Flags().GetString("type")
+			if err != nil {
+				return err
+			}
+			defaultParams, err := params.ParseAssignments(defaults)
+			if err != nil {
+				return err
+			}
+
+			var text string
+			if len(args) > 1 {
+				if info, statErr := os.Stat(args[1]); statErr == nil && !info.IsDir() {
+					text, err = app.ReadQuery(args[1])
+				} else {
+					text = args[1]
+				}
+			} else {
+				text, err = app.ReadQuery("")
+			}
+			if err != nil {
+				return err
+			}
+
+			lib, err := openLibrary()
+			if err != nil {
+				return err
+			}
+			path, err := lib.Save(library.Query{
+				Name:        args[0],
+				Description: description,
+				Language:    queryType,
+				Query:       strings.TrimSpace(text),
+				Params:      defaultParams,
+				Tags:        tags,
+			}, force)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Saved %s to %s\n", args[0], path)
+			return err
+		},
+	}
+
+	cmd.Flags().String("type", "gremlin", "Query language: 'gremlin' or 'cypher'.")
+	cmd.Flags().StringVar(&description, "description", "", "What the query answers.")
+	cmd.Flags().StringArrayVar(&tags, "tag", nil, "Tag the query for 'nq query list --tag'. Repeatable.")
+	cmd.Flags().StringArrayVar(&defaults, "param", nil, "Default value for a $name placeholder as name=value. Repeatable.")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing saved query of the same name.")
+
+	return cmd
+}
+
+func newQueryListCommand() *cobra.Command {
+	var tag string
+
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List saved queries.",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lib, err := openLibrary()
+			if err != nil {
+				return err
+			}
+			queries, problems, err := lib.List()
+			if err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				l := log.NewWithOptions(cmd.ErrOrStderr(), log.Options{
+					ReportTimestamp: false,
+				})
+				for _, problem := range problems {
+					l.Warn("skipping saved query", "error", problem)
+				}
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tLANGUAGE\tTAGS\tDESCRIPTION")
+			for _, q := range queries {
+				if tag != "" && !q.HasTag(tag) {
+					continue
+				}
+				name := q.Name
+				if q.Shared {
+					name += " (shared)"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, q.Language, strings.Join(q.Tags, ","), q.Description)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&tag, "tag", "", "Only list queries with this tag.")
+
+	return cmd
+}
+
+func newQueryShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:           "show NAME",
+		Short:         "Print a saved query.",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lib, err := openLibrary()
+			if err != nil {
+				return err
+			}
+			q, err := lib.Get(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := library.Marshal(*q)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "# %s\n%s", q.Path, data)
+			return err
+		},
+	}
+}
+
+func newQueryRunCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run NAME",
+		Short: "Run a saved query.",
+		Long: `Run a saved query.
+
+Placeholders take their values from --params-file, then --param, then the
+defaults saved with the query.
+
+Examples:
+  nq query run study-versions --param study=LY3298176
+  nq query run study-versions --param study=LY3298176 --param limit=50 -o table`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat, err := resolveOutputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			mode, err := queryModeFlags(cmd)
+			if err != nil {
+				return err
+			}
+			bindings, err := queryBindings(cmd)
+			if err != nil {
+				return err
+			}
+
+			lib, err := openLibrary()
+			if err != nil {
+				return err
+			}
+			saved, err := lib.Get(args[0])
+			if err != nil {
+				return err
+			}
+			query, err := saved.Render(bindings)
+			if err != nil {
+				return err
+			}
+
+			appService, err := newQueryService(cmd.Context(), false)
+			if err != nil {
+				return err
+			}
+
+			l := log.NewWithOptions(cmd.ErrOrStderr(), log.Options{
+				ReportTimestamp: false,
+			})
+			if !noLint {
+				logQueryLint(l, appService, query, saved.Language)
+			}
+
+			ctx := cmd.Context()
+			if mode != neptune.ModeExecute {
+				plan, err := explainQuery(ctx, appService, query, saved.Language, mode)
+				if err != nil {
+					logQueryError(l, err)
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), plan.JSON())
+				return err
+			}
+
+			res, err := appService.ExecuteQueryContext(ctx, query, saved.Language)
+			if err != nil {
+				logQueryError(l, err)
+				return err
+			}
+			return writeResult(cmd.OutOrStdout(), res, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", string(format.JSON), fmt.Sprintf("Output format: %s.", strings.Join(format.Names(), "|")))
+	cmd.Flags().StringArray("param", nil, "Bind a query parameter as name=value, overriding the saved default. Repeatable.")
+	cmd.Flags().String("params-file", "", "JSON or YAML file with a mapping of query parameters; --param values take precedence.")
+	cmd.Flags().Bool("explain", false, "Print Neptune's query plan instead of running the query.")
+	cmd.Flags().Bool("profile", false, "Run the query and print Neptune's profile instead of the result.")
+	cmd.MarkFlagsMutuallyExclusive("explain", "profile")
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestLibrary points the config file, and so the saved query library, at
+// a temporary directory.
+func useTestLibrary(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("{}\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("NQ_CONFIG", configFile)
+	t.Setenv("NQ_SHARED_QUERIES", "")
+	return filepath.Join(dir, "queries")
+}
+
+func TestQueryCommandsSaveListAndRun(t *testing.T) {
+	queriesDir := useTestLibrary(t)
+	spy := &spyQueryService{}
+	origFactory := newQueryService
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return spy, nil }
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	t.Cleanup(func() {
+		newQueryService = origFactory
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+	})
+
+	rootCmd.SetArgs([]string{"query", "save", "study-versions",
+		"--description", "Versions of a study", "--tag", "study", "--param", "limit=10",
+		"g.V().has('Study', 'name', $study).out('has_version').limit($limit)"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("query save returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(queriesDir, "study-versions.yaml")); err != nil {
+		t.Fatalf("expected the query to be saved: %v", err)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"query", "list", "--tag", "study"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("query list returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "study-versions") || !strings.Contains(out.String(), "Versions of a study") {
+		t.Fatalf("expected the saved query in the list, got:\n%s", out.String())
+	}
+
+	rootCmd.SetArgs([]string{"query", "run", "study-versions", "--param", "study=LY01"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("query run returned error: %v", err)
+	}
+	if want := "g.V().has('Study', 'name', 'LY01').out('has_version').limit(10)"; spy.lastQuery != want {
+		t.Fatalf("expected query %q, got %q", want, spy.lastQuery)
+	}
+	if spy.lastQueryType != "gremlin" {
+		t.Fatalf("expected a gremlin query, got %q", spy.lastQueryType)
+	}
+}
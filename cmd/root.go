@@ -2,13 +2,21 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ankit-lilly/nqcli/internal/app"
 	"github.com/ankit-lilly/nqcli/internal/appsyncdiscovery"
 	"github.com/ankit-lilly/nqcli/internal/config"
+	"github.com/ankit-lilly/nqcli/internal/format"
 	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/params"
+	"github.com/ankit-lilly/nqcli/internal/result"
 
 	awscfg "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/charmbracelet/lipgloss"
@@ -17,15 +25,45 @@ import (
 )
 
 type queryService interface {
-	Execute(string, string) (string, string, error)
-	ExecuteQuery(string, string) (string, string, error)
+	ExecuteContext(context.Context, string, string) (*result.QueryResult, error)
+	ExecuteQueryContext(context.Context, string, string) (*result.QueryResult, error)
+}
+
+// queryExplainer is implemented by query services that can return Neptune's
+// explain or profile output instead of a result.
+type queryExplainer interface {
+	ExplainQueryContext(ctx context.Context, query, queryType string, mode neptune.Mode) (*result.QueryResult, error)
+}
+
+// explainQuery returns the readable plan of query in mode.
+func explainQuery(ctx context.Context, appService queryService, query, queryType string, mode neptune.Mode) (*result.QueryResult, error) {
+	explainer, ok := appService.(queryExplainer)
+	if !ok {
+		return nil, fmt.Errorf("%s is not supported by this query service", mode)
+	}
+	return explainer.ExplainQueryContext(ctx, query, queryType, mode)
 }
 
 var (
-	envFilePath string
-	awsProfile  string
-	awsRegion   string
-	version     = "dev"
+	envFilePath      string
+	envName          string
+	configPath       string
+	awsProfile       string
+	awsRegion        string
+	retryMaxAttempts int
+	retryBaseDelay   time.Duration
+	retryMaxDelay    time.Duration
+	retryWrites      bool
+	readOnly         bool
+	readOnlySet      bool
+	rawGraphSON      bool
+	outputFormat     format.Format
+	version          = "dev"
+
+	// flagChanged reports whether a flag was given on the command line, so
+	// that explicit values such as --retry-writes=false override the
+	// environment.
+	flagChanged = func(string) bool { return false }
 )
 
 var newGQLClient = func(ctx context.Context) (*neptune.Client, error) {
@@ -33,14 +71,17 @@ var newGQLClient = func(ctx context.Context) (*neptune.Client, error) {
 		ctx = context.Background()
 	}
 
-	cfg := config.LoadConfig()
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	cfgOpts := []func(*awscfg.LoadOptions) error{}
-	if awsProfile != "" {
-		cfgOpts = append(cfgOpts, awscfg.WithSharedConfigProfile(awsProfile))
+	if cfg.AWSProfile != "" {
+		cfgOpts = append(cfgOpts, awscfg.WithSharedConfigProfile(cfg.AWSProfile))
 	}
-	if awsRegion != "" {
-		cfgOpts = append(cfgOpts, awscfg.WithRegion(awsRegion))
+	if cfg.AWSRegion != "" {
+		cfgOpts = append(cfgOpts, awscfg.WithRegion(cfg.AWSRegion))
 	}
 
 	awsCfg, err := awscfg.LoadDefaultConfig(ctx, cfgOpts...)
@@ -49,7 +90,7 @@ var newGQLClient = func(ctx context.Context) (*neptune.Client, error) {
 	}
 
 	if cfg.URL == "" {
-		profileName := awsProfile
+		profileName := cfg.AWSProfile
 		if profileName == "" {
 			profileName = os.Getenv("AWS_PROFILE")
 		}
@@ -71,12 +112,87 @@ var newGQLClient = func(ctx context.Context) (*neptune.Client, error) {
 	return neptune.NewClient(cfg, awsCfg)
 }
 
-var newQueryService = func(ctx context.Context) (queryService, error) {
+// loadConfig resolves configuration for the selected environment and applies
+// explicit CLI flags on top, so flags always win.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(config.LoadOptions{Env: envName, FilePath: configPath})
+	if err != nil {
+		return nil, err
+	}
+	if awsProfile != "" {
+		cfg.AWSProfile = awsProfile
+	}
+	if awsRegion != "" {
+		cfg.AWSRegion = awsRegion
+	}
+	applyRetryFlags(cfg)
+	if readOnlySet {
+		cfg.ReadOnly = &readOnly
+	}
+	if cacheTTL > 0 {
+		cfg.CacheTTL = cacheTTL
+	}
+	return cfg, nil
+}
+
+// resolveOutputFormat returns the --output flag when given, otherwise the
+// selected environment's default output, otherwise the flag default.
+func resolveOutputFormat(cmd *cobra.Command) (format.Format, error) {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+	if !cmd.Flags().Changed("output") {
+		cfg, err := loadConfig()
+		if err != nil {
+			return "", err
+		}
+		if cfg.Output != "" {
+			output = cfg.Output
+		}
+	}
+	return format.Parse(output)
+}
+
+// applyRetryFlags lets explicit CLI flags override retry settings from the
+// environment.
+func applyRetryFlags(cfg *config.Config) {
+	if flagChanged("retries") {
+		cfg.RetryMaxAttempts = retryMaxAttempts
+	}
+	if flagChanged("retry-base-delay") {
+		cfg.RetryBaseDelay = retryBaseDelay
+	}
+	if flagChanged("retry-max-delay") {
+		cfg.RetryMaxDelay = retryMaxDelay
+	}
+	if flagChanged("retry-writes") {
+		cfg.RetryWrites = retryWrites
+	}
+}
+
+// newQueryService builds the query service for the selected environment.
+// readOnlyDefault applies when neither --read-only, NQ_READ_ONLY nor the
+// config file decides.
+var newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	readOnly := readOnlyDefault
+	if cfg.ReadOnly != nil {
+		readOnly = *cfg.ReadOnly
+	}
+
 	neptuneClient, err := newGQLClient(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return app.NewAppService(neptuneClient), nil
+	opts := []app.Option{app.WithReadOnly(readOnly), app.WithRawGraphSON(rawGraphSON)}
+	if c := resultCache(cfg); c != nil {
+		opts = append(opts, app.WithCache(c))
+	}
+	return app.NewAppService(neptuneClient, opts...), nil
 }
 
 var rootCmd = &cobra.Command{
@@ -84,9 +200,11 @@ var rootCmd = &cobra.Command{
 	Short: "Execute Gremlin or Cypher queries against a Neptune GraphQL endpoint.",
 	Long: `A CLI tool to execute Gremlin or Cypher queries against a Neptune GraphQL endpoint.
 	Usage:
-	    echo "query" | nq [--type gremlin|cypher]
+	    echo "query" | nq [--type gremlin|cypher] [--output json|ndjson|table|csv|tsv|yaml|raw]
 	    nq [--type gremlin|cypher] "query"
 	    nq [--type gremlin|cypher] <query_file>
+	    nq --param name=value [--params-file params.yaml] "g.V().has('name', $name)"
+	    nq --explain|--profile "query"
 	`,
 	Args:          cobra.MaximumNArgs(1),
 	SilenceUsage:  true,
@@ -116,7 +234,41 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
-		appService, err := newQueryService(cmd.Context())
+		mode, err := queryModeFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		bindings, err := queryBindings(cmd)
+		if err != nil {
+			return err
+		}
+		if len(bindings) > 0 {
+			// Bindings are rendered into the query text, so read it up front.
+			if inlineQuery == "" {
+				if inlineQuery, err = app.ReadQuery(queryFile); err != nil {
+					return err
+				}
+			}
+			if inlineQuery, err = params.Render(inlineQuery, queryType, bindings); err != nil {
+				return err
+			}
+		}
+
+		if inlineQuery == "" && queryFile == "" {
+			// Read stdin up front so the query can be linted or explained.
+			if inlineQuery, err = app.ReadQuery(""); err != nil {
+				return err
+			}
+		}
+		queryText := func() (string, error) {
+			if inlineQuery != "" {
+				return inlineQuery, nil
+			}
+			return app.ReadQuery(queryFile)
+		}
+
+		appService, err := newQueryService(cmd.Context(), false)
 		if err != nil {
 			return err
 		}
@@ -125,31 +277,126 @@ var rootCmd = &cobra.Command{
 			ReportTimestamp: false,
 		})
 
+		if !noLint && queryType == "gremlin" {
+			query, err := queryText()
+			if err != nil {
+				return err
+			}
+			logQueryLint(l, appService, query, queryType)
+		}
+
+		ctx := cmd.Context()
+		if mode != neptune.ModeExecute {
+			query, err := queryText()
+			if err != nil {
+				return err
+			}
+			plan, err := explainQuery(ctx, appService, query, queryType, mode)
+			if err != nil {
+				logQueryError(l, err)
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), plan.JSON())
+			return err
+		}
+
 		var (
-			prettyJSON string
-			execErr    error
+			res     *result.QueryResult
+			execErr error
 		)
 
 		if inlineQuery != "" {
-			prettyJSON, _, execErr = appService.ExecuteQuery(inlineQuery, queryType)
+			res, execErr = appService.ExecuteQueryContext(ctx, inlineQuery, queryType)
 		} else {
-			prettyJSON, _, execErr = appService.Execute(queryFile, queryType)
+			res, execErr = appService.ExecuteContext(ctx, queryFile, queryType)
 		}
 		if execErr != nil {
-			style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Bold(true)
-			l.Error(style.Render("Error"), "details", execErr)
+			logQueryError(l, execErr)
 			return execErr
 		}
+		if !res.CachedAt.IsZero() {
+			l.Info("result from cache; use --no-cache for a fresh one", "age", time.Since(res.CachedAt).Round(time.Second))
+		}
 
-		fmt.Println(prettyJSON)
-		return nil
+		return writeResult(cmd.OutOrStdout(), res, outputFormat)
 	},
 }
 
+// queryModeFlags maps --explain and --profile to a query mode.
+func queryModeFlags(cmd *cobra.Command) (neptune.Mode, error) {
+	explain, err := cmd.Flags().GetBool("explain")
+	if err != nil {
+		return "", err
+	}
+	profile, err := cmd.Flags().GetBool("profile")
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case explain:
+		return neptune.ModeExplain, nil
+	case profile:
+		return neptune.ModeProfile, nil
+	}
+	return neptune.ModeExecute, nil
+}
+
+// queryBindings merges --params-file with --param; individual --param values
+// win over the file.
+func queryBindings(cmd *cobra.Command) (map[string]any, error) {
+	assignments, err := cmd.Flags().GetStringArray("param")
+	if err != nil {
+		return nil, err
+	}
+	paramsFile, err := cmd.Flags().GetString("params-file")
+	if err != nil {
+		return nil, err
+	}
+
+	var fromFile map[string]any
+	if paramsFile != "" {
+		if fromFile, err = params.LoadFile(paramsFile); err != nil {
+			return nil, err
+		}
+	}
+	fromFlags, err := params.ParseAssignments(assignments)
+	if err != nil {
+		return nil, err
+	}
+	return params.Merge(fromFile, fromFlags), nil
+}
+
+// logQueryError writes err to l, listing each GraphQL error separately so the
+// resolver's error type and path are visible.
+func logQueryError(l *log.Logger, err error) {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555")).Bold(true)
+
+	var respErr *neptune.ResponseError
+	if !errors.As(err, &respErr) || len(respErr.Errors) == 0 {
+		l.Error(style.Render("Error"), "details", err)
+		return
+	}
+
+	for _, gqlErr := range respErr.Errors {
+		keyvals := []any{"message", gqlErr.Message}
+		if gqlErr.ErrorType != "" {
+			keyvals = append(keyvals, "type", gqlErr.ErrorType)
+		}
+		if len(gqlErr.Path) > 0 {
+			keyvals = append(keyvals, "path", gqlErr.Path)
+		}
+		l.Error(style.Render("Error"), keyvals...)
+	}
+}
+
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		stop()
 		os.Exit(1)
 	}
 }
@@ -164,6 +411,18 @@ func init() {
 		"",
 		"Path to a .env file to load before executing (defaults to ./ .env, then ~/.env).",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&envName,
+		"env",
+		"",
+		"Named environment from the config file (defaults to $NQ_ENV, then default_env).",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&configPath,
+		"config",
+		"",
+		"Path to the config file (defaults to $NQ_CONFIG, then ~/.config/nqcli/config.yaml).",
+	)
 	rootCmd.PersistentFlags().StringVar(
 		&awsProfile,
 		"aws-profile",
@@ -177,7 +436,69 @@ func init() {
 		"Override the AWS region when signing AppSync requests.",
 	)
 
+	rootCmd.PersistentFlags().IntVar(
+		&retryMaxAttempts,
+		"retries",
+		0,
+		"Maximum attempts per AppSync call, including the first (default 3, env NQ_RETRY_MAX_ATTEMPTS; 1 disables retries).",
+	)
+	rootCmd.PersistentFlags().DurationVar(
+		&retryBaseDelay,
+		"retry-base-delay",
+		0,
+		"Initial backoff between retries (default 200ms, env NQ_RETRY_BASE_DELAY).",
+	)
+	rootCmd.PersistentFlags().DurationVar(
+		&retryMaxDelay,
+		"retry-max-delay",
+		0,
+		"Upper bound for a single backoff, including Retry-After (default 5s, env NQ_RETRY_MAX_DELAY).",
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&retryWrites,
+		"retry-writes",
+		false,
+		"Also retry queries that may write to the graph (env NQ_RETRY_WRITES).",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&readOnly,
+		"read-only",
+		false,
+		"Reject queries that would modify the graph (env NQ_READ_ONLY or read_only in the config file; defaults to on for 'nq mcp').",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&rawGraphSON,
+		"raw-graphson",
+		false,
+		"Show Gremlin results as Neptune returns them, with GraphSON type wrappers such as g:Int64 and g:Map, instead of plain JSON.",
+	)
+
+	rootCmd.PersistentFlags().DurationVar(
+		&cacheTTL,
+		"cache-ttl",
+		0,
+		"Reuse results of identical read queries for this long (env NQ_CACHE_TTL or cache_ttl in the config file; off by default).",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&noCache,
+		"no-cache",
+		false,
+		"Always send queries to Neptune, ignoring any configured result cache.",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&noLint,
+		"no-lint",
+		false,
+		"Do not check Gremlin queries against the graph schema for unknown labels, edges and properties before running them.",
+	)
+
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		readOnlySet = cmd.Flags().Changed("read-only")
+		flagChanged = cmd.Flags().Changed
 		if err := config.LoadEnvironment(envFilePath); err != nil {
 			return err
 		}
@@ -190,6 +511,38 @@ func init() {
 		"The type of query to execute. Must be 'gremlin' or 'cypher'.",
 	)
 
+	rootCmd.Flags().StringP(
+		"output",
+		"o",
+		string(format.JSON),
+		fmt.Sprintf("Output format: %s.", strings.Join(format.Names(), "|")),
+	)
+
+	rootCmd.Flags().StringArray(
+		"param",
+		nil,
+		"Bind a query parameter as name=value, referenced as $name. JSON values (numbers, booleans, lists, maps) are typed; anything else is a string. Repeatable.",
+	)
+
+	rootCmd.Flags().String(
+		"params-file",
+		"",
+		"JSON or YAML file with a mapping of query parameters; --param values take precedence.",
+	)
+
+	rootCmd.Flags().Bool(
+		"explain",
+		false,
+		"Print Neptune's query plan instead of running the query. The plan is printed as text whatever --output says.",
+	)
+
+	rootCmd.Flags().Bool(
+		"profile",
+		false,
+		"Run the query and print Neptune's profile (plan with per-step timings and counts) instead of the result.",
+	)
+	rootCmd.MarkFlagsMutuallyExclusive("explain", "profile")
+
 	rootCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		queryType, err := cmd.Flags().GetString("type")
 		if err != nil {
@@ -199,6 +552,10 @@ func init() {
 		if queryType != "gremlin" && queryType != "cypher" {
 			return fmt.Errorf("invalid value for --type: %s. Must be 'gremlin' or 'cypher'", queryType)
 		}
+
+		if outputFormat, err = resolveOutputFormat(cmd); err != nil {
+			return err
+		}
 		return nil
 	}
 }
@@ -1,36 +1,54 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/config"
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/spf13/cobra"
 )
 
 type spyQueryService struct {
 	executeCalls      int
 	executeQueryCalls int
+	explainCalls      int
+	lastMode          neptune.Mode
 	lastQuery         string
 	lastQueryType     string
 }
 
-func (s *spyQueryService) Execute(path, queryType string) (string, string, error) {
+func (s *spyQueryService) ExecuteContext(_ context.Context, path, queryType string) (*result.QueryResult, error) {
 	s.executeCalls++
 	s.lastQuery = path
 	s.lastQueryType = queryType
-	return "{}", "", nil
+	return result.FromPayload("{}", result.Options{}), nil
 }
 
-func (s *spyQueryService) ExecuteQuery(query, queryType string) (string, string, error) {
+func (s *spyQueryService) ExecuteQueryContext(_ context.Context, query, queryType string) (*result.QueryResult, error) {
 	s.executeQueryCalls++
 	s.lastQuery = query
 	s.lastQueryType = queryType
-	return "{}", "", nil
+	return result.FromPayload("{}", result.Options{}), nil
+}
+
+func (s *spyQueryService) ExplainQueryContext(_ context.Context, query, queryType string, mode neptune.Mode) (*result.QueryResult, error) {
+	s.explainCalls++
+	s.lastMode = mode
+	s.lastQuery = query
+	s.lastQueryType = queryType
+	return &result.QueryResult{Text: "Query plan"}, nil
 }
 
 func TestRootCommandInlineQueryCallsExecuteQuery(t *testing.T) {
 	spy := &spyQueryService{}
 	origFactory := newQueryService
-	newQueryService = func(ctx context.Context) (queryService, error) { return spy, nil }
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return spy, nil }
 	t.Cleanup(func() {
 		newQueryService = origFactory
 		rootCmd.SetArgs(nil)
@@ -59,7 +77,7 @@ func TestRootCommandInlineQueryCallsExecuteQuery(t *testing.T) {
 func TestRootCommandFileArgumentCallsExecute(t *testing.T) {
 	spy := &spyQueryService{}
 	origFactory := newQueryService
-	newQueryService = func(ctx context.Context) (queryService, error) { return spy, nil }
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return spy, nil }
 	t.Cleanup(func() {
 		newQueryService = origFactory
 		rootCmd.SetArgs(nil)
@@ -90,3 +108,63 @@ func TestRootCommandFileArgumentCallsExecute(t *testing.T) {
 		t.Fatalf("expected query type 'gremlin', got %q", spy.lastQueryType)
 	}
 }
+
+func TestRootCommandExplainPrintsPlan(t *testing.T) {
+	spy := &spyQueryService{}
+	origFactory := newQueryService
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return spy, nil }
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	t.Cleanup(func() {
+		newQueryService = origFactory
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		_ = rootCmd.Flags().Set("profile", "false")
+	})
+
+	rootCmd.SetArgs([]string{"--type", "cypher", "--profile", "MATCH (n) RETURN n"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("rootCmd.Execute() returned error: %v", err)
+	}
+
+	if spy.explainCalls != 1 {
+		t.Fatalf("expected ExplainQueryContext to be called once, got %d", spy.explainCalls)
+	}
+	if spy.executeCalls != 0 || spy.executeQueryCalls != 0 {
+		t.Fatalf("expected the query not to be executed, got %d/%d calls", spy.executeCalls, spy.executeQueryCalls)
+	}
+	if spy.lastMode != neptune.ModeProfile {
+		t.Fatalf("expected mode %q, got %q", neptune.ModeProfile, spy.lastMode)
+	}
+	if spy.lastQuery != "MATCH (n) RETURN n" {
+		t.Fatalf("expected query 'MATCH (n) RETURN n', got %q", spy.lastQuery)
+	}
+	if got := strings.TrimSpace(out.String()); got != "Query plan" {
+		t.Fatalf("expected the plan on stdout, got %q", got)
+	}
+}
+
+func TestApplyRetryFlagsOverridesEnvironment(t *testing.T) {
+	flags := (&cobra.Command{}).Flags()
+	flags.IntVar(&retryMaxAttempts, "retries", 0, "")
+	flags.DurationVar(&retryBaseDelay, "retry-base-delay", 0, "")
+	flags.DurationVar(&retryMaxDelay, "retry-max-delay", 0, "")
+	flags.BoolVar(&retryWrites, "retry-writes", false, "")
+	origChanged := flagChanged
+	t.Cleanup(func() { flagChanged = origChanged })
+	flagChanged = flags.Changed
+
+	if err := flags.Parse([]string{"--retries", "1", "--retry-writes=false"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	cfg := &config.Config{RetryMaxAttempts: 5, RetryBaseDelay: time.Second, RetryWrites: true}
+	applyRetryFlags(cfg)
+
+	if cfg.RetryMaxAttempts != 1 || cfg.RetryWrites {
+		t.Fatalf("expected the flags to override the environment, got %+v", cfg)
+	}
+	if cfg.RetryBaseDelay != time.Second {
+		t.Fatalf("expected an unset flag to keep the environment value, got %v", cfg.RetryBaseDelay)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/batch"
+	"github.com/ankit-lilly/nqcli/internal/format"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+// resultExtensions maps output formats to the extension of result files.
+var resultExtensions = map[format.Format]string{
+	format.JSON:   ".json",
+	format.NDJSON: ".ndjson",
+	format.Table:  ".txt",
+	format.CSV:    ".csv",
+	format.TSV:    ".tsv",
+	format.YAML:   ".yaml",
+	format.Raw:    ".json",
+}
+
+func init() {
+	rootCmd.AddCommand(newRunCommand())
+}
+
+func newRunCommand() *cobra.Command {
+	var (
+		concurrency int
+		timeout     time.Duration
+		outDir      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run <dir|file>...",
+		Short: "Run every query in a set of files or directories and summarize the results.",
+		Long: `Run every query in a set of files or directories and summarize the results.
+
+Directories are searched recursively for .gremlin, .groovy, .cypher and .cql
+files, and the language of each file comes from its extension; files named
+directly whose extension does not say use --type. A file may hold several
+statements separated by ';'.
+
+Each result is written to --out-dir, mirroring the layout of the query files,
+with a summary.json of every query's status and duration. A failed query's
+error is written to NAME.error.txt and the command exits non-zero once all
+queries have run.
+
+Examples:
+  nq run healthchecks/
+  nq run -j 4 --out-dir /tmp/checks -o csv healthchecks/ extra.gremlin`,
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queryType, err := cmd.Flags().GetString("type")
+			if err != nil {
+				return err
+			}
+			if queryType != "gremlin" && queryType != "cypher" {
+				return fmt.Errorf("invalid value for --type: %s. Must be 'gremlin' or 'cypher'", queryType)
+			}
+			outputFormat, err := resolveOutputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+
+			queries, err := batch.Load(args, queryType)
+			if err != nil {
+				return err
+			}
+			if len(queries) == 0 {
+				return fmt.Errorf("no queries found in %s", strings.Join(args, ", "))
+			}
+
+			appService, err := newQueryService(cmd.Context(), false)
+			if err != nil {
+				return err
+			}
+
+			l := log.NewWithOptions(cmd.ErrOrStderr(), log.Options{
+				ReportTimestamp: false,
+			})
+			if !noLint {
+				for _, q := range queries {
+					logQueryLint(l.With("query", q.Name), appService, q.Text, q.Type)
+				}
+			}
+
+			start := time.Now()
+			outcomes := batch.Run(cmd.Context(), appService, queries, batch.Options{
+				Concurrency: concurrency,
+				Timeout:     timeout,
+				Done: func(outcome batch.Outcome) {
+					if outcome.Err != nil {
+						l.Error("query failed", "query", outcome.Name, "elapsed", outcome.Duration.Round(time.Millisecond))
+						return
+					}
+					l.Info("query finished", "query", outcome.Name, "elapsed", outcome.Duration.Round(time.Millisecond))
+				},
+			})
+			elapsed := time.Since(start)
+
+			outputs := make([]string, len(outcomes))
+			for i, outcome := range outcomes {
+				if outputs[i], err = writeBatchOutput(outDir, outcome, outputFormat); err != nil {
+					return err
+				}
+			}
+
+			summary := batch.Summarize(outcomes, outputs, elapsed)
+			if err := writeBatchSummary(outDir, summary); err != nil {
+				return err
+			}
+			if err := summary.Write(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			if summary.Failed > 0 {
+				return fmt.Errorf("%d of %d queries failed", summary.Failed, summary.Total)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("type", "gremlin", "Query language for files whose extension does not say: 'gremlin' or 'cypher'.")
+	cmd.Flags().StringP("output", "o", string(format.JSON), fmt.Sprintf("Format of the result files: %s.", strings.Join(format.Names(), "|")))
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "j", 1, "How many queries to run at once.")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Time limit for each query (0 for none).")
+	cmd.Flags().StringVar(&outDir, "out-dir", "nq-results", "Directory to write results and summary.json to.")
+
+	return cmd
+}
+
+// writeBatchOutput writes the result of one query, or its error, under dir
+// and returns the file's path. The other kind of file left for the query by
+// an earlier run into dir is removed, so a query never has both a result and
+// an error.
+func writeBatchOutput(dir string, outcome batch.Outcome, f format.Format) (string, error) {
+	base := filepath.Join(dir, filepath.FromSlash(outcome.Name))
+	path, stale := base+resultExtensions[f], base+".error.txt"
+	if outcome.Err != nil {
+		path, stale = stale, path
+	}
+	if err := os.Remove(stale); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to remove stale %s: %w", stale, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create result file: %w", err)
+	}
+
+	if outcome.Err != nil {
+		_, err = fmt.Fprintf(file, "%s\n\nquery (%s):\n%s\n", outcome.Err, outcome.Type, outcome.Text)
+		if err == nil && outcome.Result != nil && outcome.Result.Raw != "" {
+			_, err = fmt.Fprintf(file, "\nresponse:\n%s\n", outcome.Result.Raw)
+		}
+	} else {
+		err = outcome.Result.Write(file, f, format.Options{})
+	}
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, file.Close()
+}
+
+func writeBatchSummary(dir string, summary batch.Summary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, "summary.json"), append(data, '\n'), 0o644)
+}
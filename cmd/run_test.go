@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ankit-lilly/nqcli/internal/batch"
+	"github.com/ankit-lilly/nqcli/internal/format"
+	"github.com/ankit-lilly/nqcli/internal/result"
+)
+
+func TestRunCommandWritesResultsAndSummary(t *testing.T) {
+	spy := &spyQueryService{}
+	origFactory := newQueryService
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return spy, nil }
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	t.Cleanup(func() {
+		newQueryService = origFactory
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+	})
+
+	dir := t.TempDir()
+	queries := filepath.Join(dir, "checks")
+	if err := os.MkdirAll(queries, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(queries, "counts.gremlin"), []byte("g.V().count();\ng.E().count();\n"), 0o644); err != nil {
+		t.Fatalf("write query: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(queries, "studies.cypher"), []byte("MATCH (s:Study) RETURN count(s)"), 0o644); err != nil {
+		t.Fatalf("write query: %v", err)
+	}
+	outDir := filepath.Join(dir, "results")
+
+	rootCmd.SetArgs([]string{"run", "--out-dir", outDir, queries})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("rootCmd.Execute() returned error: %v", err)
+	}
+
+	if spy.executeQueryCalls != 3 {
+		t.Fatalf("expected 3 queries to run, got %d", spy.executeQueryCalls)
+	}
+	if spy.lastQueryType != "cypher" {
+		t.Fatalf("expected the .cypher file to run as cypher, got %q", spy.lastQueryType)
+	}
+	for _, name := range []string{"counts-1.json", "counts-2.json", "studies.json"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Fatalf("expected result file %s: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "summary.json"))
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	var summary batch.Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("parse summary: %v", err)
+	}
+	if summary.Total != 3 || summary.Succeeded != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if !strings.Contains(out.String(), "Succeeded: 3") {
+		t.Fatalf("expected the summary on stdout, got:\n%s", out.String())
+	}
+}
+
+func TestWriteBatchOutputReplacesTheOtherOutcome(t *testing.T) {
+	dir := t.TempDir()
+	query := batch.Query{Name: "checks/counts", Type: "gremlin", Text: "g.V().count()"}
+	succeeded := batch.Outcome{Query: query, Result: result.FromPayload("[1]", result.Options{})}
+	failed := batch.Outcome{Query: query, Err: errors.New("boom")}
+	resultPath := filepath.Join(dir, "checks", "counts.json")
+	errorPath := filepath.Join(dir, "checks", "counts.error.txt")
+
+	for _, step := range []struct {
+		outcome      batch.Outcome
+		want, absent string
+	}{
+		{succeeded, resultPath, errorPath},
+		{failed, errorPath, resultPath},
+		{succeeded, resultPath, errorPath},
+	} {
+		path, err := writeBatchOutput(dir, step.outcome, format.JSON)
+		if err != nil || path != step.want {
+			t.Fatalf("writeBatchOutput = %q, %v; want %q", path, err, step.want)
+		}
+		if _, err := os.Stat(step.absent); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected %s to be removed, got %v", step.absent, err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ankit-lilly/nqcli/internal/schema"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+// errSchemaDrift is returned by nq schema diff after reporting differences,
+// so the process exits non-zero.
+var errSchemaDrift = errors.New("schema drift detected")
+
+func init() {
+	rootCmd.AddCommand(newSchemaCommand())
+}
+
+func newSchemaCommand() *cobra.Command {
+	var (
+		source     string
+		formatName string
+		refresh    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the graph schema or export it as Markdown, Mermaid, DOT or JSON Schema.",
+		Long: `Print the graph schema: vertex labels with their properties, edge labels and
+the edge patterns connecting them.
+
+The static schema needs no connection. It is embedded in nq unless the selected
+environment sets schema_file (or NQ_SCHEMA_FILE) to a maintained copy. The
+dynamic schema is discovered from the live graph (and cached like the MCP
+server's, see NQ_SCHEMA_CACHE_TTL); it adds counts and low-cardinality property
+values.
+
+Examples:
+  nq schema
+  nq schema --format markdown > docs/data-model.md
+  nq schema --source dynamic --env dev --format mermaid
+  nq schema --format dot | dot -Tsvg > schema.svg`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := schema.ParseSource(source)
+			if err != nil {
+				return err
+			}
+			f, err := schema.ParseFormat(formatName)
+			if err != nil {
+				return err
+			}
+
+			// The static document carries notes and instance types the
+			// common representation drops, so JSON prints it verbatim.
+			if src == schema.Static && f == schema.JSON {
+				doc, err := loadStaticSchema()
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), doc.JSON())
+				return err
+			}
+
+			dynamicSchemaCache.Refresh = refresh
+			graph, err := loadSchemaGraph(cmd.Context(), src)
+			if err != nil {
+				return err
+			}
+			logSchemaWarnings(cmd.ErrOrStderr(), graph)
+			return schema.Write(cmd.OutOrStdout(), graph, f)
+		},
+	}
+
+	cmd.Flags().StringVar(&source, "source", string(schema.Static), fmt.Sprintf("Schema source: %s.", strings.Join(schema.SourceNames(), "|")))
+	cmd.Flags().StringVar(&formatName, "format", string(schema.JSON), fmt.Sprintf("Export format: %s.", strings.Join(schema.FormatNames(), "|")))
+	cmd.PersistentFlags().BoolVar(&refresh, "refresh-schema", false, "Ignore the on-disk schema cache and rediscover the dynamic schema.")
+
+	cmd.AddCommand(newSchemaDiffCommand(&refresh))
+
+	return cmd
+}
+
+func newSchemaDiffCommand(refresh *bool) *cobra.Command {
+	var (
+		formatName   string
+		allowMissing bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the static schema with the schema discovered from the live graph.",
+		Long: `Compare the static schema's vertex labels, edge labels, edge patterns and
+vertex properties with those discovered from the live graph.
+
+Entries marked "-" are declared but were not found in the graph; entries marked
+"+" are in the graph but not declared. The command exits non-zero when the
+schemas differ, so it can gate CI against a dev environment. Discovery that
+fails part-way is an error, since an incomplete schema would report false drift.
+
+Examples:
+  nq schema diff --env dev
+  nq schema diff --env dev --allow-missing --format json`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if formatName != "text" && formatName != "json" {
+				return fmt.Errorf("invalid value for --format: %s. Must be 'text' or 'json'", formatName)
+			}
+
+			expected, err := loadSchemaGraph(cmd.Context(), schema.Static)
+			if err != nil {
+				return err
+			}
+			dynamicSchemaCache.Refresh = *refresh
+			actual, err := loadSchemaGraph(cmd.Context(), schema.Dynamic)
+			if err != nil {
+				return err
+			}
+			if len(actual.Warnings) > 0 {
+				logSchemaWarnings(cmd.ErrOrStderr(), actual)
+				return fmt.Errorf("schema discovery incomplete (%d warnings); not comparing", len(actual.Warnings))
+			}
+
+			diff := schema.Compare(expected, actual)
+			if formatName == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(diff)
+			} else {
+				err = diff.WriteText(cmd.OutOrStdout())
+			}
+			if err != nil {
+				return err
+			}
+
+			if diff.HasExtra() || (diff.HasMissing() && !allowMissing) {
+				return errSchemaDrift
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&formatName, "format", "text", "Report format: text|json.")
+	cmd.Flags().BoolVar(&allowMissing, "allow-missing", false, "Only fail when the graph has labels, edges or properties the schema does not declare; declared entries without data are still reported.")
+
+	return cmd
+}
+
+// loadSchemaGraph returns the schema from src. Unlike get_graph_schema, an
+// explicit dynamic request does not fall back to the static schema.
+func loadSchemaGraph(ctx context.Context, src schema.Source) (*schema.Graph, error) {
+	if src == schema.Static {
+		doc, err := loadStaticSchema()
+		if err != nil {
+			return nil, err
+		}
+		return doc.Graph(), nil
+	}
+
+	appService, err := newQueryService(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := discoverSchema(ctx, appService, nil)
+	if err != nil {
+		return nil, fmt.Errorf("discover schema: %w", err)
+	}
+	return graph, nil
+}
+
+func logSchemaWarnings(w io.Writer, graph *schema.Graph) {
+	if len(graph.Warnings) == 0 {
+		return
+	}
+	l := log.NewWithOptions(w, log.Options{ReportTimestamp: false})
+	for _, warning := range graph.Warnings {
+		l.Warn("schema discovery incomplete", "step", warning)
+	}
+}
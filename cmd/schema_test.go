@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ankit-lilly/nqcli/internal/result"
+)
+
+func TestSchemaCommandExportsStaticSchema(t *testing.T) {
+	cmd := newSchemaCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "mermaid"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "erDiagram\n") || !strings.Contains(out.String(), `Study ||--o{ StudyVersion : "has_version"`) {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestSchemaCommandDynamicDoesNotFallBack(t *testing.T) {
+	service := &stubQueryService{execErr: errors.New("boom")}
+	origFactory := newQueryService
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return service, nil }
+	t.Cleanup(func() { newQueryService = origFactory })
+
+	cmd := newSchemaCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--source", "dynamic", "--format", "markdown"})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected discovery error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output, got %q", out.String())
+	}
+}
+
+// liveGraphService answers discovery queries for a graph holding only
+// Study -has_version-> StudyVersion, plus any extra vertex labels.
+type liveGraphService struct {
+	extraLabels []string
+}
+
+func (s *liveGraphService) ExecuteContext(context.Context, string, string) (*result.QueryResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *liveGraphService) ExecuteQueryContext(_ context.Context, query, _ string) (*result.QueryResult, error) {
+	switch {
+	case query == "g.V().label().dedup()":
+		labels, _ := json.Marshal(append([]string{"Study", "StudyVersion"}, s.extraLabels...))
+		return result.FromPayload(string(labels), result.Options{}), nil
+	case query == "g.E().label().dedup()":
+		return result.FromPayload(`["has_version"]`, result.Options{}), nil
+	case strings.HasPrefix(query, "g.E().project("):
+		return result.FromPayload(`[{"out":"Study","label":"has_version","in":"StudyVersion"}]`, result.Options{}), nil
+	case strings.HasPrefix(query, "g.V().hasLabel('Study').properties()"):
+		return result.FromPayload(`["name"]`, result.Options{}), nil
+	case strings.HasSuffix(query, ".count()"):
+		return result.FromPayload(`1`, result.Options{}), nil
+	}
+	return result.FromPayload(`[]`, result.Options{}), nil
+}
+
+func runSchemaDiff(t *testing.T, service queryService, args ...string) (string, error) {
+	t.Helper()
+	origFactory := newQueryService
+	newQueryService = func(ctx context.Context, readOnlyDefault bool) (queryService, error) { return service, nil }
+	t.Cleanup(func() { newQueryService = origFactory })
+
+	cmd := newSchemaCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"diff"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSchemaDiffFailsOnDrift(t *testing.T) {
+	out, err := runSchemaDiff(t, &liveGraphService{})
+	if !errors.Is(err, errSchemaDrift) {
+		t.Fatalf("expected drift error, got %v", err)
+	}
+	if !strings.Contains(out, "  - StudyDesign\n") || !strings.Contains(out, "    - description\n") {
+		t.Fatalf("expected missing labels and properties in report:\n%s", out)
+	}
+
+	// Declared entries without data are tolerated with --allow-missing.
+	if _, err := runSchemaDiff(t, &liveGraphService{}, "--allow-missing"); err != nil {
+		t.Fatalf("expected --allow-missing to pass, got %v", err)
+	}
+
+	// Undeclared labels still fail.
+	out, err = runSchemaDiff(t, &liveGraphService{extraLabels: []string{"Audit"}}, "--allow-missing", "--format", "json")
+	if !errors.Is(err, errSchemaDrift) {
+		t.Fatalf("expected drift error for undeclared label, got %v", err)
+	}
+	var report struct {
+		ExtraVertexLabels []string `json:"extra_vertex_labels"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil || len(report.ExtraVertexLabels) != 1 {
+		t.Fatalf("unexpected JSON report (%v):\n%s", err, out)
+	}
+}
+
+func TestSchemaCommandUsesSchemaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	content := `{"root_label": "Trial", "schema": {"Trial": {"sites": {"edgeLabel": "has_site", "childLabel": "Site"}}}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	t.Setenv("NQ_SCHEMA_FILE", path)
+
+	cmd := newSchemaCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "mermaid"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(out.String(), `Trial ||--o{ Site : "has_site"`) {
+		t.Fatalf("expected the file's schema, got:\n%s", out.String())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"root_label": "Trial"}`), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	cmd = newSchemaCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "schema: at least one vertex label is required") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ankit-lilly/nqcli/internal/lint"
 	httpserver "github.com/ankit-lilly/nqcli/internal/server"
 
 	"github.com/charmbracelet/log"
@@ -30,19 +31,37 @@ func newServerCommand() *cobra.Command {
 				return err
 			}
 
-			appService, err := newQueryService(cmd.Context())
+			cacheInMemory = true
+			appService, err := newQueryService(cmd.Context(), false)
 			if err != nil {
 				return err
 			}
 
+			var opts []httpserver.Option
+			if !noLint {
+				static, err := loadStaticSchema()
+				if err != nil {
+					return err
+				}
+				opts = append(opts, httpserver.WithLinter(func(query, queryType string) []lint.Warning {
+					return queryLinter(appService, static).Check(query, queryType)
+				}))
+			}
+
+			lib, err := openLibrary()
+			if err != nil {
+				return err
+			}
+			opts = append(opts, httpserver.WithLibrary(lib.List))
+
 			logger := log.NewWithOptions(os.Stderr, log.Options{
 				ReportTimestamp: true,
 				TimeFormat:      time.RFC3339,
 			})
 
-			server := httpserver.New(appService, logger)
+			server := httpserver.New(appService, logger, opts...)
 
-			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
 
 			if err := server.Start(ctx, addr); err != nil && !errors.Is(err, context.Canceled) {
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/config"
+	"github.com/ankit-lilly/nqcli/internal/format"
+
+	"github.com/charmbracelet/log"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+)
+
+const shellHelp = `Statements may span several lines and run once terminated by ';'.
+
+Meta-commands:
+  :type gremlin|cypher   Switch the query language.
+  :output FORMAT         Switch the output format (json, ndjson, table, csv, tsv, yaml, raw).
+  :env [NAME]            Reconnect to a named config file environment, or list them.
+  :schema                Print the graph schema.
+  :help                  Show this help.
+  :quit                  Leave the shell (Ctrl-D works too).
+`
+
+func init() {
+	rootCmd.AddCommand(newShellCommand())
+}
+
+func newShellCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Start an interactive query shell that reuses one AppSync connection.",
+		Long: `Start an interactive shell for running Gremlin or Cypher queries.
+
+The AWS configuration and AppSync endpoint are resolved once and reused for
+every statement. Statements end with ';' and may span multiple lines. History
+is kept in ~/.cache/nqcli/history.
+
+` + shellHelp,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queryType, err := cmd.Flags().GetString("type")
+			if err != nil {
+				return err
+			}
+			if queryType != "gremlin" && queryType != "cypher" {
+				return fmt.Errorf("invalid value for --type: %s. Must be 'gremlin' or 'cypher'", queryType)
+			}
+			outputFormat, err := resolveOutputFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			cacheInMemory = true
+			appService, err := newQueryService(cmd.Context(), false)
+			if err != nil {
+				return err
+			}
+
+			session := &shellSession{
+				service:   appService,
+				queryType: queryType,
+				output:    outputFormat,
+				env:       envName,
+				connect: func(ctx context.Context, env string) (queryService, error) {
+					previous := envName
+					envName = env
+					service, err := newQueryService(ctx, false)
+					if err != nil {
+						envName = previous
+					}
+					return service, err
+				},
+				environments: func() []string {
+					file, err := config.LoadFile(configPath)
+					if err != nil {
+						return nil
+					}
+					return file.EnvironmentNames()
+				},
+				out:  cmd.OutOrStdout(),
+				lint: !noLint,
+				logger: log.NewWithOptions(cmd.ErrOrStderr(), log.Options{
+					ReportTimestamp: false,
+				}),
+			}
+
+			return session.run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().String("type", "gremlin", "Initial query language: 'gremlin' or 'cypher'.")
+	cmd.Flags().StringP("output", "o", string(format.JSON), fmt.Sprintf("Initial output format: %s.", strings.Join(format.Names(), "|")))
+
+	return cmd
+}
+
+// shellSession holds the state of an interactive shell. Input is fed one line
+// at a time so the statement and meta-command handling is independent of the
+// terminal library.
+type shellSession struct {
+	service   queryService
+	queryType string
+	output    format.Format
+	env       string
+	connect   func(ctx context.Context, env string) (queryService, error)
+	out       io.Writer
+	logger    *log.Logger
+	// lint checks Gremlin statements against the schema before they run.
+	lint bool
+
+	environments func() []string
+
+	pending []string
+	history []string
+}
+
+func (s *shellSession) run(ctx context.Context) error {
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetMultiLineMode(true)
+
+	histPath, histErr := shellHistoryPath()
+	if histErr == nil {
+		if f, err := os.Open(histPath); err == nil {
+			_, _ = line.ReadHistory(f)
+			f.Close()
+		}
+	}
+	defer func() {
+		if histErr != nil {
+			return
+		}
+		if err := saveShellHistory(line, histPath); err != nil {
+			s.logger.Warn("failed to save history", "path", histPath, "error", err)
+		}
+	}()
+
+	fmt.Fprintln(s.out, "nq shell. Type :help for commands, :quit to exit.")
+
+	// Ctrl-C during a query cancels only that query, so statements run on a
+	// context detached from the command's signal handling.
+	base := context.WithoutCancel(ctx)
+	for {
+		input, err := line.Prompt(s.prompt())
+		switch {
+		case errors.Is(err, liner.ErrPromptAborted):
+			s.pending = nil
+			continue
+		case errors.Is(err, io.EOF):
+			fmt.Fprintln(s.out)
+			return nil
+		case err != nil:
+			return fmt.Errorf("read input: %w", err)
+		}
+
+		quit := s.feed(base, input)
+		for _, entry := range s.history {
+			line.AppendHistory(entry)
+		}
+		s.history = nil
+		if quit {
+			return nil
+		}
+	}
+}
+
+func (s *shellSession) prompt() string {
+	if len(s.pending) > 0 {
+		return strings.Repeat(" ", len(s.queryType)+1) + "-> "
+	}
+	return s.queryType + "> "
+}
+
+// feed processes one line of input and reports whether the shell should exit.
+func (s *shellSession) feed(ctx context.Context, input string) bool {
+	trimmed := strings.TrimSpace(input)
+	if len(s.pending) == 0 {
+		if trimmed == "" {
+			return false
+		}
+		if strings.HasPrefix(trimmed, ":") {
+			s.history = append(s.history, trimmed)
+			return s.meta(ctx, trimmed)
+		}
+	}
+
+	s.pending = append(s.pending, input)
+	if !strings.HasSuffix(trimmed, ";") {
+		return false
+	}
+
+	statement := strings.TrimSpace(strings.Join(s.pending, "\n"))
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+	s.pending = nil
+	if statement == "" {
+		return false
+	}
+
+	s.history = append(s.history, strings.Join(strings.Fields(statement), " ")+";")
+	s.execute(ctx, statement)
+	return false
+}
+
+func (s *shellSession) execute(ctx context.Context, statement string) {
+	queryCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if s.lint {
+		logQueryLint(s.logger, s.service, statement, s.queryType)
+	}
+
+	start := time.Now()
+	res, err := s.service.ExecuteQueryContext(queryCtx, statement, s.queryType)
+	elapsed := time.Since(start)
+	if err != nil {
+		logQueryError(s.logger, err)
+		s.logger.Info("query failed", "elapsed", elapsed.Round(time.Millisecond))
+		return
+	}
+
+	if err := writeResult(s.out, res, s.output); err != nil {
+		s.logger.Error("failed to render result", "error", err)
+	}
+	keyvals := []any{"elapsed", elapsed.Round(time.Millisecond), "bytes", res.Bytes}
+	if !res.CachedAt.IsZero() {
+		keyvals = append(keyvals, "cached", time.Since(res.CachedAt).Round(time.Second).String()+" ago")
+	}
+	s.logger.Info("query finished", keyvals...)
+}
+
+func (s *shellSession) meta(ctx context.Context, input string) bool {
+	fields := strings.Fields(strings.TrimPrefix(input, ":"))
+	if len(fields) == 0 {
+		return false
+	}
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "q", "quit", "exit":
+		return true
+	case "help", "h", "?":
+		fmt.Fprint(s.out, shellHelp)
+	case "type":
+		if len(args) != 1 || (args[0] != "gremlin" && args[0] != "cypher") {
+			s.logger.Error("usage: :type gremlin|cypher", "current", s.queryType)
+			return false
+		}
+		s.queryType = args[0]
+	case "output":
+		if len(args) != 1 {
+			s.logger.Error("usage: :output FORMAT", "current", s.output, "formats", strings.Join(format.Names(), ", "))
+			return false
+		}
+		f, err := format.Parse(args[0])
+		if err != nil {
+			s.logger.Error(err.Error())
+			return false
+		}
+		s.output = f
+	case "env":
+		if len(args) == 0 {
+			s.listEnvironments()
+			return false
+		}
+		if len(args) != 1 {
+			s.logger.Error("usage: :env [NAME]", "current", s.env)
+			return false
+		}
+		service, err := s.connect(ctx, args[0])
+		if err != nil {
+			s.logger.Error("failed to switch environment", "env", args[0], "error", err)
+			return false
+		}
+		s.service = service
+		s.env = args[0]
+		s.logger.Info("switched environment", "env", s.env)
+	case "schema":
+		static, err := loadStaticSchema()
+		if err != nil {
+			s.logger.Error("failed to load schema", "error", err)
+			return false
+		}
+		schema, err := buildGraphSchema(ctx, s.service, static, nil)
+		if err != nil {
+			s.logger.Error("failed to load schema", "error", err)
+			return false
+		}
+		fmt.Fprintln(s.out, schema)
+	default:
+		s.logger.Error("unknown command; type :help for a list", "command", ":"+command)
+	}
+	return false
+}
+
+func (s *shellSession) listEnvironments() {
+	var names []string
+	if s.environments != nil {
+		names = s.environments()
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(s.out, "No environments configured.")
+		return
+	}
+	for _, name := range names {
+		marker := "  "
+		if name == s.env {
+			marker = "* "
+		}
+		fmt.Fprintln(s.out, marker+name)
+	}
+}
+
+func shellHistoryPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nqcli", "history"), nil
+}
+
+func saveShellHistory(line *liner.State, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := line.WriteHistory(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
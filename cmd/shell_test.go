@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ankit-lilly/nqcli/internal/format"
+
+	"github.com/charmbracelet/log"
+)
+
+func newTestShellSession(service queryService) (*shellSession, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &shellSession{
+		service:   service,
+		queryType: "gremlin",
+		output:    format.JSON,
+		out:       &out,
+		logger:    log.NewWithOptions(io.Discard, log.Options{}),
+	}, &out
+}
+
+func TestShellRunsMultiLineStatementOnSemicolon(t *testing.T) {
+	spy := &spyQueryService{}
+	session, out := newTestShellSession(spy)
+	ctx := context.Background()
+
+	session.feed(ctx, "g.V().hasLabel('Study')")
+	if spy.executeQueryCalls != 0 {
+		t.Fatalf("expected statement to wait for ';', got %d calls", spy.executeQueryCalls)
+	}
+	session.feed(ctx, "  .count();")
+
+	if spy.executeQueryCalls != 1 {
+		t.Fatalf("expected one ExecuteQuery call, got %d", spy.executeQueryCalls)
+	}
+	if spy.lastQuery != "g.V().hasLabel('Study')\n  .count()" {
+		t.Fatalf("unexpected statement %q", spy.lastQuery)
+	}
+	if out.String() != "{}\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	if len(session.history) != 1 || session.history[0] != "g.V().hasLabel('Study') .count();" {
+		t.Fatalf("unexpected history %q", session.history)
+	}
+}
+
+func TestShellMetaCommands(t *testing.T) {
+	spy := &spyQueryService{}
+	session, _ := newTestShellSession(spy)
+	ctx := context.Background()
+
+	var connectedTo string
+	session.connect = func(_ context.Context, env string) (queryService, error) {
+		connectedTo = env
+		return spy, nil
+	}
+
+	session.feed(ctx, ":type cypher")
+	session.feed(ctx, ":output table")
+	session.feed(ctx, ":env qa")
+	session.feed(ctx, "MATCH (n) RETURN count(n);")
+
+	if session.queryType != "cypher" || spy.lastQueryType != "cypher" {
+		t.Fatalf("expected cypher, got session=%q query=%q", session.queryType, spy.lastQueryType)
+	}
+	if session.output != format.Table {
+		t.Fatalf("expected table output, got %q", session.output)
+	}
+	if connectedTo != "qa" || session.env != "qa" {
+		t.Fatalf("expected to reconnect to qa, got %q", connectedTo)
+	}
+	if !session.feed(ctx, ":quit") {
+		t.Fatalf("expected :quit to end the session")
+	}
+}
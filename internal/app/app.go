@@ -1,85 +1,231 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/ankit-lilly/nqcli/internal/cache"
 	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/ankit-lilly/nqcli/internal/safety"
 )
 
 type AppService struct {
 	neptuneClient *neptune.Client
+	readOnly      bool
+	rawGraphSON   bool
+	cache         *cache.Cache
 }
 
-func NewAppService(nc *neptune.Client) *AppService {
-	return &AppService{
+// Option configures an AppService.
+type Option func(*AppService)
+
+// WithReadOnly rejects queries that would modify the graph before they are
+// sent to AppSync.
+func WithReadOnly(readOnly bool) Option {
+	return func(s *AppService) {
+		s.readOnly = readOnly
+	}
+}
+
+// WithRawGraphSON keeps GraphSON type wrappers such as g:Int64 and g:Map in
+// results instead of decoding them to plain JSON.
+func WithRawGraphSON(raw bool) Option {
+	return func(s *AppService) {
+		s.rawGraphSON = raw
+	}
+}
+
+// WithCache answers read queries from c when it holds a fresh response for
+// the same endpoint, language and query, and stores successful responses in
+// it. Queries that may modify the graph always go to Neptune.
+func WithCache(c *cache.Cache) Option {
+	return func(s *AppService) {
+		s.cache = c
+	}
+}
+
+// WithoutCache returns a copy of s that always sends queries to Neptune,
+// for callers such as schema discovery that must see the graph as it is now.
+func (s *AppService) WithoutCache() *AppService {
+	uncached := *s
+	uncached.cache = nil
+	return &uncached
+}
+
+func NewAppService(nc *neptune.Client, opts ...Option) *AppService {
+	s := &AppService{
 		neptuneClient: nc,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *AppService) Execute(queryFilePath string, queryType string) (processedOutput string, rawJSONResponse string, err error) {
-	query, err := s.readQueryContent(queryFilePath)
+// Endpoint returns the AppSync URL queries are sent to.
+func (s *AppService) Endpoint() string {
+	return s.neptuneClient.Endpoint()
+}
+
+// ReadOnly reports whether mutating queries are rejected.
+func (s *AppService) ReadOnly() bool {
+	return s.readOnly
+}
+
+func (s *AppService) Execute(queryFilePath string, queryType string) (*result.QueryResult, error) {
+	return s.ExecuteContext(context.Background(), queryFilePath, queryType)
+}
+
+// ExecuteContext reads a query from queryFilePath (or stdin when empty) and
+// runs it, aborting the AppSync call when ctx is done.
+func (s *AppService) ExecuteContext(ctx context.Context, queryFilePath string, queryType string) (*result.QueryResult, error) {
+	query, err := ReadQuery(queryFilePath)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return s.ExecuteQuery(query, queryType)
+	return s.ExecuteQueryContext(ctx, query, queryType)
 }
 
-func (s *AppService) ExecuteQuery(query string, queryType string) (processedOutput string, rawJSONResponse string, err error) {
+func (s *AppService) ExecuteQuery(query string, queryType string) (*result.QueryResult, error) {
+	return s.ExecuteQueryContext(context.Background(), query, queryType)
+}
+
+// ExecuteQueryContext runs query and returns its decoded result. GraphSON in
+// the result is decoded to plain JSON unless the service was built
+// WithRawGraphSON. When AppSync answers with an error the result is returned
+// too, carrying the raw response. Cancelling ctx aborts the in-flight request.
+// With a cache, a fresh cached response is returned instead, marked by
+// CachedAt.
+func (s *AppService) ExecuteQueryContext(ctx context.Context, query string, queryType string) (*result.QueryResult, error) {
 	if strings.TrimSpace(query) == "" {
-		return "", "", fmt.Errorf("query content is empty")
+		return nil, fmt.Errorf("query content is empty")
+	}
+	if s.readOnly {
+		if err := safety.Check(query, queryType); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := result.Options{RawGraphSON: s.rawGraphSON}
+	read := !safety.IsMutating(query, queryType)
+	var cacheKey string
+	if s.cache != nil && read {
+		cacheKey = cache.Key(s.Endpoint(), queryType, query)
+		if raw, storedAt, ok := s.cache.Get(cacheKey); ok {
+			if res, err := result.FromResponse(raw, opts); err == nil {
+				res.Type, res.Query, res.CachedAt = queryType, query, storedAt
+				return res, nil
+			}
+		}
 	}
 
-	rawJSONResponse, err = s.neptuneClient.ExecuteQuery(query, queryType)
+	start := time.Now()
+	raw, err := s.neptuneClient.ExecuteQueryContext(ctx, query, queryType, read)
+	elapsed := time.Since(start)
 	if err != nil {
-		return "", rawJSONResponse, fmt.Errorf("neptune query failed: %w", err)
+		return bareResult(raw, query, queryType, elapsed), fmt.Errorf("neptune query failed: %w", err)
 	}
 
-	var responseMap map[string]any
-	if err := json.Unmarshal([]byte(rawJSONResponse), &responseMap); err != nil {
-		return rawJSONResponse, rawJSONResponse, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+	res, err := result.FromResponse(raw, opts)
+	res.Type, res.Query, res.Duration = queryType, query, elapsed
+	if err == nil && cacheKey != "" {
+		s.cache.Put(cacheKey, raw)
 	}
+	return res, err
+}
 
-	data, ok := responseMap["data"].(map[string]any)
+// bareResult returns a result holding only the raw response and metadata,
+// for queries AppSync rejected and for plans, which have no value.
+func bareResult(raw, query, queryType string, elapsed time.Duration) *result.QueryResult {
+	return &result.QueryResult{
+		Type:     queryType,
+		Query:    query,
+		Raw:      raw,
+		Bytes:    len(raw),
+		Duration: elapsed,
+	}
+}
 
-	if !ok || data == nil {
-		processedOutput = rawJSONResponse
-	} else {
-		executeQuery, ok := data["executeQuery"].(string)
-		if !ok {
-			processedOutput = rawJSONResponse
-		} else {
-			var innerData any
-			if err := json.Unmarshal([]byte(executeQuery), &innerData); err != nil {
-				processedOutput = executeQuery
-			} else {
-
-				finalOutputData := innerData
-
-				if obj, isObject := innerData.(map[string]any); isObject {
-					if finalData, finalDataExists := obj["data"]; finalDataExists {
-						finalOutputData = finalData
-					}
-				}
-
-				prettyJSON, marshalErr := json.MarshalIndent(finalOutputData, "", "  ")
-				if marshalErr != nil {
-					processedOutput = executeQuery // Fallback
-				} else {
-					processedOutput = string(prettyJSON)
-				}
-			}
+// ExplainQueryContext asks Neptune how it runs query instead of for its
+// result and returns the plan as readable text in the result's Text.
+// ModeExplain only plans the query; ModeProfile also runs it, so in
+// read-only mode it is rejected for queries that modify the graph.
+// ModeExecute is the same as ExecuteQueryContext.
+func (s *AppService) ExplainQueryContext(ctx context.Context, query string, queryType string, mode neptune.Mode) (*result.QueryResult, error) {
+	if mode == neptune.ModeExecute {
+		return s.ExecuteQueryContext(ctx, query, queryType)
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query content is empty")
+	}
+	if s.readOnly && mode == neptune.ModeProfile {
+		if err := safety.Check(query, queryType); err != nil {
+			return nil, err
 		}
 	}
 
-	return processedOutput, rawJSONResponse, nil
+	start := time.Now()
+	// Explaining never runs the query, so it is safe to retry; profiling
+	// runs it and is retried only for reads.
+	idempotent := mode == neptune.ModeExplain || !safety.IsMutating(query, queryType)
+	raw, err := s.neptuneClient.ExplainQueryContext(ctx, query, queryType, mode, idempotent)
+	elapsed := time.Since(start)
+	res := bareResult(raw, query, queryType, elapsed)
+	if err != nil {
+		return res, fmt.Errorf("neptune %s failed: %w", mode, err)
+	}
+
+	var response struct {
+		Data struct {
+			ExecuteQuery *string `json:"executeQuery"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(raw), &response); err != nil {
+		res.Text = raw
+		return res, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+	}
+	res.Text = raw
+	if response.Data.ExecuteQuery != nil {
+		res.Text = renderPlan(*response.Data.ExecuteQuery)
+	}
+	return res, nil
+}
+
+// renderPlan makes an explain or profile payload readable. Neptune returns
+// Gremlin plans as text and openCypher plans as text or JSON; the resolver
+// may wrap either in a JSON string or a {"data": ...} object. Text is
+// returned unquoted and JSON is indented.
+func renderPlan(payload string) string {
+	text := strings.TrimSpace(payload)
+	var decoded any
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return text
+	}
+	if obj, ok := decoded.(map[string]any); ok {
+		if data, ok := obj["data"]; ok && len(obj) == 1 {
+			decoded = data
+		}
+	}
+	if nested, ok := decoded.(string); ok {
+		return renderPlan(nested)
+	}
+	indented, err := json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(indented)
 }
 
-func (s *AppService) readQueryContent(queryFilePath string) (string, error) {
+// ReadQuery returns the contents of queryFilePath, or of stdin when the path
+// is empty and stdin is not a terminal.
+func ReadQuery(queryFilePath string) (string, error) {
 	var reader io.Reader
 
 	if queryFilePath != "" {
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/cache"
+	"github.com/ankit-lilly/nqcli/internal/config"
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestExecuteQueryContextCachesReads(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"data":{"executeQuery":"[42]"}}`))
+	}))
+	defer server.Close()
+
+	client, err := neptune.NewClient(
+		&config.Config{URL: server.URL},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	service := NewAppService(client, WithCache(cache.NewMemory(cache.Options{TTL: time.Minute})))
+	ctx := context.Background()
+
+	first, err := service.ExecuteQueryContext(ctx, "g.V().count()", "gremlin")
+	if err != nil {
+		t.Fatalf("ExecuteQueryContext: %v", err)
+	}
+	second, err := service.ExecuteQueryContext(ctx, "g.V().count();\n", "gremlin")
+	if err != nil {
+		t.Fatalf("ExecuteQueryContext: %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("expected the repeated read to be served from the cache, got %d requests", got)
+	}
+	if !first.CachedAt.IsZero() || second.CachedAt.IsZero() {
+		t.Fatalf("expected only the second result to be marked cached, got %v and %v", first.CachedAt, second.CachedAt)
+	}
+	if second.JSON() != first.JSON() {
+		t.Fatalf("expected the cached result %s to match %s", second.JSON(), first.JSON())
+	}
+
+	for range 2 {
+		if _, err := service.ExecuteQueryContext(ctx, "g.addV('Study')", "gremlin"); err != nil {
+			t.Fatalf("ExecuteQueryContext: %v", err)
+		}
+	}
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("expected mutating queries to bypass the cache, got %d requests", got)
+	}
+
+	fresh, err := service.WithoutCache().ExecuteQueryContext(ctx, "g.V().count()", "gremlin")
+	if err != nil {
+		t.Fatalf("ExecuteQueryContext: %v", err)
+	}
+	if got := requests.Load(); got != 4 || !fresh.CachedAt.IsZero() {
+		t.Fatalf("expected WithoutCache to query Neptune, got %d requests and CachedAt %v", got, fresh.CachedAt)
+	}
+}
+
+func TestExecuteQueryContextRetriesOnlyReads(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := neptune.NewClient(
+		&config.Config{URL: server.URL, RetryMaxAttempts: 3, RetryBaseDelay: time.Millisecond},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	service := NewAppService(client)
+	ctx := context.Background()
+
+	if _, err := service.ExecuteQueryContext(ctx, "g.V().count()", "gremlin"); err == nil {
+		t.Fatalf("expected the read to fail")
+	}
+	if got := requests.Swap(0); got != 3 {
+		t.Fatalf("expected a read to be retried, got %d requests", got)
+	}
+
+	if _, err := service.ExecuteQueryContext(ctx, "g.addV('Study')", "gremlin"); err == nil {
+		t.Fatalf("expected the write to fail")
+	}
+	if got := requests.Swap(0); got != 1 {
+		t.Fatalf("expected a write not to be retried, got %d requests", got)
+	}
+
+	if _, err := service.ExplainQueryContext(ctx, "g.addV('Study')", "gremlin", neptune.ModeExplain); err == nil {
+		t.Fatalf("expected the explain to fail")
+	}
+	if got := requests.Swap(0); got != 3 {
+		t.Fatalf("expected explaining a write to be retried, got %d requests", got)
+	}
+}
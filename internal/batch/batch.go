@@ -0,0 +1,253 @@
+// Package batch runs many queries loaded from files and directories, such as
+// a folder of health-check queries, and summarizes how each one went.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/result"
+)
+
+// extensionTypes maps query file extensions to query languages. Directories
+// are searched for these extensions only.
+var extensionTypes = map[string]string{
+	".gremlin": "gremlin",
+	".groovy":  "gremlin",
+	".cypher":  "cypher",
+	".cql":     "cypher",
+}
+
+// Query is one statement to run.
+type Query struct {
+	// Name identifies the query in output file names and the summary: the
+	// file path relative to the directory it was found in, without its
+	// extension, and "-N" for the Nth statement of a file holding several.
+	Name string
+	Path string
+	// Index is the 1-based position of the statement in its file.
+	Index int
+	Type  string
+	Text  string
+}
+
+// Load reads the queries in paths. Directories are walked for files with a
+// known query extension; files named directly are read whatever their
+// extension. The language comes from the extension, or defaultType when it
+// does not say. Each file is split into statements at ';'.
+func Load(paths []string, defaultType string) ([]Query, error) {
+	var queries []Query
+	names := make(map[string]bool)
+	add := func(path, name string) error {
+		loaded, err := loadFile(path, name, defaultType)
+		if err != nil {
+			return err
+		}
+		for _, q := range loaded {
+			q.Name = uniqueName(q.Name, names)
+			queries = append(queries, q)
+		}
+		return nil
+	}
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(root, strings.TrimSuffix(filepath.Base(root), filepath.Ext(root))); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var files []string
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && extensionTypes[strings.ToLower(filepath.Ext(path))] != "" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(files)
+		for _, path := range files {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if err := add(path, filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return queries, nil
+}
+
+func loadFile(path, name, defaultType string) ([]Query, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query file: %w", err)
+	}
+	queryType := extensionTypes[strings.ToLower(filepath.Ext(path))]
+	if queryType == "" {
+		queryType = defaultType
+	}
+
+	statements := Split(string(content))
+	queries := make([]Query, len(statements))
+	for i, statement := range statements {
+		queries[i] = Query{Name: name, Path: path, Index: i + 1, Type: queryType, Text: statement}
+		if len(statements) > 1 {
+			queries[i].Name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+	}
+	return queries, nil
+}
+
+// uniqueName returns name, or name with a numeric suffix when an earlier
+// query already took it, and records the result in taken.
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for n := 2; taken[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// Split returns the statements in text, separated by ';'. Semicolons inside
+// string literals, backtick-quoted names and comments do not split, and
+// statements holding only whitespace and comments are dropped.
+func Split(text string) []string {
+	var (
+		statements []string
+		start      int
+		hasCode    bool
+	)
+	flush := func(end int) {
+		if statement := strings.TrimSpace(text[start:end]); hasCode && statement != "" {
+			statements = append(statements, statement)
+		}
+		start, hasCode = end+1, false
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			hasCode = true
+			for i++; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		case strings.HasPrefix(text[i:], "//"):
+			if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(text)
+			}
+		case strings.HasPrefix(text[i:], "/*"):
+			if end := strings.Index(text[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(text)
+			}
+		case c == ';':
+			flush(i)
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			hasCode = true
+		}
+	}
+	flush(len(text))
+	return statements
+}
+
+// Executor runs one query.
+type Executor interface {
+	ExecuteQueryContext(ctx context.Context, query, queryType string) (*result.QueryResult, error)
+}
+
+// Outcome is how one query went. Result may be set even when Err is, if
+// AppSync answered with an error.
+type Outcome struct {
+	Query
+	Result   *result.QueryResult
+	Err      error
+	Duration time.Duration
+}
+
+// Options controls Run.
+type Options struct {
+	// Concurrency is how many queries run at once; values below 1 mean 1.
+	Concurrency int
+	// Timeout bounds each query. Zero means no limit beyond ctx.
+	Timeout time.Duration
+	// Done, when set, is called after each query finishes. Calls are
+	// serialized.
+	Done func(Outcome)
+}
+
+// Run executes queries on exec and returns their outcomes in the order of
+// queries. A failing query does not stop the others; queries that have not
+// started when ctx is done fail with ctx.Err().
+func Run(ctx context.Context, exec Executor, queries []Query, opts Options) []Outcome {
+	outcomes := make([]Outcome, len(queries))
+	slots := make(chan struct{}, max(opts.Concurrency, 1))
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	done := func(outcome Outcome) {
+		if opts.Done == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		opts.Done(outcome)
+	}
+
+	for i, q := range queries {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(queries); j++ {
+				outcomes[j] = Outcome{Query: queries[j], Err: ctx.Err()}
+				done(outcomes[j])
+			}
+			wg.Wait()
+			return outcomes
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			queryCtx := ctx
+			if opts.Timeout > 0 {
+				var cancel context.CancelFunc
+				queryCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			res, err := exec.ExecuteQueryContext(queryCtx, q.Text, q.Type)
+			outcomes[i] = Outcome{Query: q, Result: res, Err: err, Duration: time.Since(start)}
+			done(outcomes[i])
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
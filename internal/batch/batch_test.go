@@ -0,0 +1,155 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/result"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"single statement", "g.V().count()\n", []string{"g.V().count()"}},
+		{"trailing semicolon", "g.V().count();\n\n", []string{"g.V().count()"}},
+		{"several statements", "g.V().count();\ng.E().count();", []string{"g.V().count()", "g.E().count()"}},
+		{"semicolons in strings", `g.V().has('name', 'a;b'); g.V().has("x", "c\";d")`, []string{`g.V().has('name', 'a;b')`, `g.V().has("x", "c\";d")`}},
+		{"semicolons in comments", "// first; check\ng.V().count();\n/* a; b */ g.E().count()", []string{"// first; check\ng.V().count()", "/* a; b */ g.E().count()"}},
+		{"comment-only statements dropped", "g.V().count();\n// done;\n", []string{"g.V().count()"}},
+		{"backtick names", "MATCH (n:`a;b`) RETURN n", []string{"MATCH (n:`a;b`) RETURN n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.text); !slices.Equal(got, tt.want) {
+				t.Fatalf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "checks", "counts.gremlin"), "g.V().count();\ng.E().count();\n")
+	writeFile(t, filepath.Join(dir, "checks", "nested", "studies.cypher"), "MATCH (s:Study) RETURN count(s)")
+	writeFile(t, filepath.Join(dir, "checks", "README.md"), "not a query; skipped")
+	writeFile(t, filepath.Join(dir, "extra.txt"), "MATCH (n) RETURN n")
+
+	queries, err := Load([]string{filepath.Join(dir, "checks"), filepath.Join(dir, "extra.txt")}, "cypher")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var got []string
+	for _, q := range queries {
+		got = append(got, q.Name+" "+q.Type+" "+q.Text)
+	}
+	want := []string{
+		"counts-1 gremlin g.V().count()",
+		"counts-2 gremlin g.E().count()",
+		"nested/studies cypher MATCH (s:Study) RETURN count(s)",
+		"extra cypher MATCH (n) RETURN n",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Load = %q, want %q", got, want)
+	}
+}
+
+func TestLoadMakesNamesUnique(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a", "count.gremlin"), "g.V().count()")
+	writeFile(t, filepath.Join(dir, "b", "count.gremlin"), "g.E().count()")
+
+	queries, err := Load([]string{filepath.Join(dir, "a", "count.gremlin"), filepath.Join(dir, "b", "count.gremlin")}, "gremlin")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(queries) != 2 || queries[0].Name != "count" || queries[1].Name != "count_2" {
+		t.Fatalf("expected names count and count_2, got %+v", queries)
+	}
+}
+
+type slowExecutor struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (e *slowExecutor) ExecuteQueryContext(_ context.Context, query, _ string) (*result.QueryResult, error) {
+	e.mu.Lock()
+	e.inFlight++
+	e.maxInFlight = max(e.maxInFlight, e.inFlight)
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.inFlight--
+		e.mu.Unlock()
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("boom")
+	}
+	return result.FromPayload("[1]", result.Options{}), nil
+}
+
+func TestRunLimitsConcurrencyAndKeepsOrder(t *testing.T) {
+	var queries []Query
+	for _, text := range []string{"q1", "q2", "fail", "q4", "q5", "q6"} {
+		queries = append(queries, Query{Name: text, Type: "gremlin", Text: text})
+	}
+
+	exec := &slowExecutor{}
+	done := 0
+	outcomes := Run(context.Background(), exec, queries, Options{
+		Concurrency: 2,
+		Done:        func(Outcome) { done++ },
+	})
+
+	if exec.maxInFlight > 2 {
+		t.Fatalf("expected at most 2 queries in flight, got %d", exec.maxInFlight)
+	}
+	if done != len(queries) {
+		t.Fatalf("expected Done for every query, got %d", done)
+	}
+	for i, outcome := range outcomes {
+		if outcome.Name != queries[i].Name {
+			t.Fatalf("outcome %d is for %q, want %q", i, outcome.Name, queries[i].Name)
+		}
+		if failed := outcome.Err != nil; failed != (outcome.Name == "fail") {
+			t.Fatalf("unexpected error for %q: %v", outcome.Name, outcome.Err)
+		}
+	}
+
+	summary := Summarize(outcomes, nil, time.Second)
+	if summary.Succeeded != 5 || summary.Failed != 1 {
+		t.Fatalf("expected 5 succeeded and 1 failed, got %+v", summary)
+	}
+	var report strings.Builder
+	if err := summary.Write(&report); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !strings.Contains(report.String(), "FAIL  fail") || !strings.Contains(report.String(), "error: boom") {
+		t.Fatalf("expected the failure in the report, got:\n%s", report.String())
+	}
+}
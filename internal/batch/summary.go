@@ -0,0 +1,100 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Summary totals a batch run. It is written as JSON next to the results.
+type Summary struct {
+	Total      int            `json:"total"`
+	Succeeded  int            `json:"succeeded"`
+	Failed     int            `json:"failed"`
+	DurationMs int64          `json:"durationMs"`
+	Queries    []QuerySummary `json:"queries"`
+}
+
+// QuerySummary is the summary line of one query.
+type QuerySummary struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Index      int    `json:"index"`
+	Type       string `json:"type"`
+	OK         bool   `json:"ok"`
+	DurationMs int64  `json:"durationMs"`
+	Bytes      int    `json:"bytes,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+	// Output is the file the result or error was written to, if any.
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Summarize totals outcomes. outputs holds the file each outcome was written
+// to, by index, and may be nil.
+func Summarize(outcomes []Outcome, outputs []string, elapsed time.Duration) Summary {
+	summary := Summary{
+		Total:      len(outcomes),
+		DurationMs: elapsed.Milliseconds(),
+		Queries:    make([]QuerySummary, len(outcomes)),
+	}
+	for i, outcome := range outcomes {
+		line := QuerySummary{
+			Name:       outcome.Name,
+			Path:       outcome.Path,
+			Index:      outcome.Index,
+			Type:       outcome.Type,
+			OK:         outcome.Err == nil,
+			DurationMs: outcome.Duration.Milliseconds(),
+		}
+		if outcome.Result != nil {
+			line.Bytes = outcome.Result.Bytes
+			line.RequestID = outcome.Result.RequestID
+		}
+		if i < len(outputs) {
+			line.Output = outputs[i]
+		}
+		if outcome.Err != nil {
+			line.Error = outcome.Err.Error()
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+		summary.Queries[i] = line
+	}
+	return summary
+}
+
+// Write prints the summary as a report with one line per query.
+func (s Summary) Write(w io.Writer) error {
+	width := len("QUERY")
+	for _, q := range s.Queries {
+		width = max(width, len(q.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-4s  %-*s  %-7s  %8s\n", "", width, "QUERY", "TYPE", "TIME")
+	for _, q := range s.Queries {
+		status := "OK"
+		if !q.OK {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%-4s  %-*s  %-7s  %8s\n", status, width, q.Name, q.Type, formatDuration(q.DurationMs))
+		if !q.OK {
+			fmt.Fprintf(&b, "      error: %s\n", q.Error)
+		}
+	}
+	fmt.Fprintf(&b, "\nTotal: %d  |  Succeeded: %d  |  Failed: %d  |  %s\n",
+		s.Total, s.Succeeded, s.Failed, formatDuration(s.DurationMs))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatDuration(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
@@ -0,0 +1,161 @@
+// Package cache keeps query responses for a while so that rerunning an
+// identical read query against a slowly changing graph does not cost another
+// round trip to Neptune. Responses are keyed by endpoint, language and
+// normalized query text, and kept either in memory, for long-running
+// commands, or on disk, so one-shot CLI runs can share them.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultMaxBytes bounds a cache whose options do not.
+const DefaultMaxBytes = 64 << 20
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long a response is served after it was stored.
+	TTL time.Duration
+	// MaxBytes bounds the total size of stored responses; the least
+	// recently used are evicted first. A response larger than a quarter of
+	// it is not stored, so one huge result cannot flush the rest. Zero
+	// selects DefaultMaxBytes.
+	MaxBytes int64
+}
+
+// Cache stores query responses for Options.TTL. It is safe for concurrent
+// use, and failures to read or write the disk store only cost a miss.
+type Cache struct {
+	store store
+	ttl   time.Duration
+	limit int64
+	now   func() time.Time
+}
+
+type store interface {
+	get(key string) (entry, bool)
+	put(key string, e entry)
+	remove(key string)
+}
+
+type entry struct {
+	Raw      string    `json:"raw"`
+	StoredAt time.Time `json:"storedAt"`
+}
+
+func (e entry) size() int64 {
+	return int64(len(e.Raw))
+}
+
+// NewMemory returns a cache held in memory for the life of the process.
+func NewMemory(opts Options) *Cache {
+	opts = withDefaults(opts)
+	return &Cache{store: newMemoryStore(opts.MaxBytes), ttl: opts.TTL, limit: opts.MaxBytes / 4, now: time.Now}
+}
+
+// NewDisk returns a cache stored as one file per response in dir, which is
+// created on first use.
+func NewDisk(dir string, opts Options) *Cache {
+	opts = withDefaults(opts)
+	return &Cache{store: newDiskStore(dir, opts.MaxBytes), ttl: opts.TTL, limit: opts.MaxBytes / 4, now: time.Now}
+}
+
+func withDefaults(opts Options) Options {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	return opts
+}
+
+// DefaultDir returns the disk cache location, nqcli/results under the
+// user's cache directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nqcli", "results"), nil
+}
+
+// Key identifies a query against an endpoint. Parameters are rendered into
+// the query text before it runs, so different values give different keys.
+func Key(endpoint, language, query string) string {
+	sum := sha256.Sum256([]byte(endpoint + "\x00" + strings.ToLower(language) + "\x00" + Normalize(query)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the response stored under key and when it was stored, if it
+// is younger than the TTL.
+func (c *Cache) Get(key string) (raw string, storedAt time.Time, ok bool) {
+	e, ok := c.store.get(key)
+	if !ok {
+		return "", time.Time{}, false
+	}
+	if c.now().Sub(e.StoredAt) >= c.ttl {
+		c.store.remove(key)
+		return "", time.Time{}, false
+	}
+	return e.Raw, e.StoredAt, true
+}
+
+// Put stores raw under key unless it is too large to keep.
+func (c *Cache) Put(key, raw string) {
+	if int64(len(raw)) > c.limit {
+		return
+	}
+	c.store.put(key, entry{Raw: raw, StoredAt: c.now()})
+}
+
+// Normalize collapses runs of whitespace outside string literals and quoted
+// identifiers to a single space, or to a single newline when the run spans
+// lines, and drops trailing semicolons, so queries that differ only in layout
+// share a cache entry. Line breaks are kept because they end // comments.
+func Normalize(query string) string {
+	var (
+		b       strings.Builder
+		quote   rune
+		space   bool
+		newline bool
+	)
+	runes := []rune(strings.TrimSpace(query))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if quote != 0 {
+			b.WriteRune(r)
+			switch {
+			case r == '\\' && quote != '`' && i+1 < len(runes):
+				i++
+				b.WriteRune(runes[i])
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		if unicode.IsSpace(r) {
+			space = true
+			newline = newline || r == '\n' || r == '\r'
+			continue
+		}
+		if space {
+			if newline {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(' ')
+			}
+			space, newline = false, false
+		}
+		if r == '\'' || r == '"' || r == '`' {
+			quote = r
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(b.String(), "; \n")
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"g.V().count()", "g.V().count()"},
+		{"  g.V()\n\t.hasLabel('Study')\n  .count();\n", "g.V()\n.hasLabel('Study')\n.count()"},
+		{"g.V()   .count() ;", "g.V() .count()"},
+		{"g.V() // all vertices\r\n\n  .count()", "g.V() // all vertices\n.count()"},
+		{"g.V().has('name', 'two  spaces')", "g.V().has('name', 'two  spaces')"},
+		{"MATCH (n:`odd  label`)   RETURN n ;", "MATCH (n:`odd  label`) RETURN n"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.query); got != tt.want {
+			t.Fatalf("Normalize(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := Key("https://dev/graphql", "gremlin", "g.V().count()")
+	if Key("https://dev/graphql", "Gremlin", "\n  g.V().count();\n") != base {
+		t.Fatalf("expected layout and language case not to change the key")
+	}
+	commented := Key("https://dev/graphql", "gremlin", "g.V() // comment\n.count()")
+	if commented == Key("https://dev/graphql", "gremlin", "g.V() // comment .count()") {
+		t.Fatalf("expected a line break ending a // comment to change the key")
+	}
+	for _, other := range []string{
+		Key("https://prod/graphql", "gremlin", "g.V().count()"),
+		Key("https://dev/graphql", "cypher", "g.V().count()"),
+		Key("https://dev/graphql", "gremlin", "g.E().count()"),
+	} {
+		if other == base {
+			t.Fatalf("expected endpoint, language and query to change the key")
+		}
+	}
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemory(Options{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+
+	c.Put("k", `{"data":1}`)
+	if raw, storedAt, ok := c.Get("k"); !ok || raw != `{"data":1}` || !storedAt.Equal(now) {
+		t.Fatalf("expected a hit, got %q %v %v", raw, storedAt, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, _, ok := c.Get("k"); ok {
+		t.Fatalf("expected the entry to expire after the TTL")
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemory(Options{TTL: time.Hour, MaxBytes: 40})
+	c.Put("a", strings.Repeat("a", 10))
+	c.Put("b", strings.Repeat("b", 10))
+	c.Put("c", strings.Repeat("c", 10))
+	c.Get("a")
+	c.Put("d", strings.Repeat("d", 10))
+	c.Put("e", strings.Repeat("e", 10))
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true, "e": true} {
+		if _, _, ok := c.Get(key); ok != want {
+			t.Fatalf("Get(%q) hit = %v, want %v", key, ok, want)
+		}
+	}
+
+	c.Put("big", strings.Repeat("x", 11))
+	if _, _, ok := c.Get("big"); ok {
+		t.Fatalf("expected a response over a quarter of MaxBytes not to be stored")
+	}
+}
+
+func TestDiskCacheRoundTripAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	c := NewDisk(dir, Options{TTL: time.Hour, MaxBytes: 400})
+
+	c.Put("first", strings.Repeat("1", 90))
+	if raw, _, ok := NewDisk(dir, Options{TTL: time.Hour}).Get("first"); !ok || raw != strings.Repeat("1", 90) {
+		t.Fatalf("expected another cache on the same directory to see the entry, got %q %v", raw, ok)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(c.store.(*diskStore).path("first"), old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	for _, key := range []string{"second", "third", "fourth"} {
+		c.Put(key, strings.Repeat("2", 90))
+	}
+
+	if _, _, ok := c.Get("first"); ok {
+		t.Fatalf("expected the least recently used entry to be pruned")
+	}
+	if _, _, ok := c.Get("fourth"); !ok {
+		t.Fatalf("expected the newest entry to be kept")
+	}
+}
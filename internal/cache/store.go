@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	entryExt         = ".json"
+	entryTempPattern = "result-*.tmp"
+)
+
+// memoryStore is a least recently used map bounded by total response size.
+type memoryStore struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry entry
+}
+
+func newMemoryStore(maxBytes int64) *memoryStore {
+	return &memoryStore{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (s *memoryStore) get(key string) (entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return entry{}, false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*memoryItem).entry, true
+}
+
+func (s *memoryStore) put(key string, e entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(key)
+	s.entries[key] = s.order.PushFront(&memoryItem{key: key, entry: e})
+	s.bytes += e.size()
+	for s.bytes > s.maxBytes && s.order.Len() > 0 {
+		s.removeLocked(s.order.Back().Value.(*memoryItem).key)
+	}
+}
+
+func (s *memoryStore) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(key)
+}
+
+func (s *memoryStore) removeLocked(key string) {
+	elem, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	s.bytes -= elem.Value.(*memoryItem).entry.size()
+	s.order.Remove(elem)
+	delete(s.entries, key)
+}
+
+// diskStore keeps one JSON file per entry. A file's modification time marks
+// its last use, and the least recently used files are removed once the
+// directory outgrows maxBytes.
+type diskStore struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+}
+
+func newDiskStore(dir string, maxBytes int64) *diskStore {
+	return &diskStore{dir: dir, maxBytes: maxBytes}
+}
+
+func (s *diskStore) path(key string) string {
+	return filepath.Join(s.dir, key+entryExt)
+}
+
+func (s *diskStore) get(key string) (entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		_ = os.Remove(path)
+		return entry{}, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return e, true
+}
+
+func (s *diskStore) put(key string, e entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(s.dir, entryTempPattern)
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return
+	}
+	s.prune()
+}
+
+func (s *diskStore) remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = os.Remove(s.path(key))
+}
+
+// prune removes the least recently used entries until the directory fits
+// maxBytes.
+func (s *diskStore) prune() {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var (
+		files []file
+		total int64
+	)
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), entryExt) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{filepath.Join(s.dir, dirEntry.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	if total <= s.maxBytes {
+		return
+	}
+
+	slices.SortFunc(files, func(a, b file) int { return a.modTime.Compare(b.modTime) })
+	for _, f := range files {
+		if total <= s.maxBytes {
+			return
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
@@ -4,18 +4,59 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
+	// Env is the name of the selected config file environment, if any.
+	Env string
+
 	URL            string
 	AppSyncAPIName string
 	AppSyncAPIID   string
+	AWSProfile     string
+	AWSRegion      string
+
+	// ReadOnly is nil when neither the environment nor a flag set it, leaving
+	// the choice to the command.
+	ReadOnly *bool
+	// Output is the default output format for this environment.
+	Output string
+	// SchemaFile is the static schema document for this environment; empty
+	// selects the schema embedded in nq.
+	SchemaFile string
+
+	// Retry settings for AppSync calls. Zero values select the client defaults;
+	// RetryMaxAttempts of 1 disables retries.
+	RetryMaxAttempts int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	// RetryWrites also retries queries that may mutate the graph.
+	RetryWrites bool
+
+	// CacheTTL is how long query results are reused; zero disables the
+	// result cache.
+	CacheTTL time.Duration
+	// CacheMaxBytes bounds the result cache; zero selects its default.
+	CacheMaxBytes int64
 }
 
+const (
+	envRetryMaxAttempts = "NQ_RETRY_MAX_ATTEMPTS"
+	envRetryBaseDelay   = "NQ_RETRY_BASE_DELAY"
+	envRetryMaxDelay    = "NQ_RETRY_MAX_DELAY"
+	envRetryWrites      = "NQ_RETRY_WRITES"
+	envReadOnly         = "NQ_READ_ONLY"
+	envSchemaFile       = "NQ_SCHEMA_FILE"
+	envCacheTTL         = "NQ_CACHE_TTL"
+	envCacheMaxBytes    = "NQ_CACHE_MAX_BYTES"
+)
+
 var (
 	defaultEnvOnce sync.Once
 )
@@ -105,14 +146,163 @@ func ensureDefaultEnvLoaded() {
 	})
 }
 
-func LoadConfig() *Config {
+// LoadOptions selects the config file and environment for LoadConfig.
+type LoadOptions struct {
+	// Env names an environment from the config file. Empty falls back to
+	// $NQ_ENV and then the file's default_env.
+	Env string
+	// FilePath overrides the config file location.
+	FilePath string
+}
+
+// LoadConfig resolves settings with the following precedence, highest first:
+// command-line flags (applied by the caller), the config file environment
+// named by opts.Env, environment variables (including a loaded .env file), the
+// config file environment chosen by $NQ_ENV or default_env, and built-in
+// defaults.
+func LoadConfig(opts LoadOptions) (*Config, error) {
 	ensureDefaultEnvLoaded()
 
+	file, err := LoadFile(opts.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	name, env, err := file.Environment(opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	if env == nil {
+		env = &Environment{}
+	}
+	// An environment asked for by name beats environment variables, which
+	// may come from a .env file meant for another environment.
+	explicit := strings.TrimSpace(opts.Env) != ""
+	setting := func(key, fileValue string) string {
+		if explicit {
+			return firstNonEmpty(fileValue, os.Getenv(key))
+		}
+		return firstNonEmpty(os.Getenv(key), fileValue)
+	}
+
+	// The URL, API name and API ID all pick the endpoint, so they come from
+	// a single source: mixing them could send one environment's queries to
+	// another environment's endpoint.
+	endpoint := endpointFromEnv()
+	fileEndpoint := appSyncEndpoint{url: env.URL, apiName: env.APIName, apiID: env.APIID}
+	if (explicit && !fileEndpoint.empty()) || endpoint.empty() {
+		endpoint = fileEndpoint
+	}
+
 	cfg := &Config{
-		URL:            os.Getenv("NEPTUNE_URL"),
-		AppSyncAPIName: strings.TrimSpace(os.Getenv("NEPTUNE_APPSYNC_API_NAME")),
-		AppSyncAPIID:   strings.TrimSpace(os.Getenv("NEPTUNE_APPSYNC_API_ID")),
+		Env:            name,
+		URL:            strings.TrimSpace(endpoint.url),
+		AppSyncAPIName: strings.TrimSpace(endpoint.apiName),
+		AppSyncAPIID:   strings.TrimSpace(endpoint.apiID),
+		AWSProfile:     setting("AWS_PROFILE", env.AWSProfile),
+		AWSRegion:      setting("AWS_REGION", env.AWSRegion),
+		ReadOnly:       env.ReadOnly,
+		Output:         strings.TrimSpace(env.Output),
+	}
+
+	if cfg.RetryMaxAttempts, err = intFromEnv(envRetryMaxAttempts); err != nil {
+		return nil, err
+	}
+	if cfg.RetryBaseDelay, err = durationFromEnv(envRetryBaseDelay); err != nil {
+		return nil, err
+	}
+	if cfg.RetryMaxDelay, err = durationFromEnv(envRetryMaxDelay); err != nil {
+		return nil, err
+	}
+	if cfg.RetryWrites, err = boolFromEnv(envRetryWrites); err != nil {
+		return nil, err
+	}
+	if ttl := setting(envCacheTTL, env.CacheTTL); ttl != "" {
+		if cfg.CacheTTL, err = time.ParseDuration(ttl); err != nil {
+			return nil, fmt.Errorf("invalid cache TTL %q: %w", ttl, err)
+		}
+	}
+	maxBytes, err := intFromEnv(envCacheMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	cfg.CacheMaxBytes = env.CacheMaxBytes
+	if maxBytes > 0 && (!explicit || cfg.CacheMaxBytes == 0) {
+		cfg.CacheMaxBytes = int64(maxBytes)
+	}
+	if schemaFile := setting(envSchemaFile, env.SchemaFile); schemaFile != "" {
+		if cfg.SchemaFile, err = expandPath(schemaFile); err != nil {
+			return nil, fmt.Errorf("invalid schema file %q: %w", schemaFile, err)
+		}
 	}
+	if strings.TrimSpace(os.Getenv(envReadOnly)) != "" && (!explicit || cfg.ReadOnly == nil) {
+		readOnly, err := boolFromEnv(envReadOnly)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ReadOnly = &readOnly
+	}
+
+	return cfg, nil
+}
+
+// appSyncEndpoint holds the settings that select the AppSync endpoint.
+type appSyncEndpoint struct {
+	url, apiName, apiID string
+}
+
+func endpointFromEnv() appSyncEndpoint {
+	return appSyncEndpoint{
+		url:     os.Getenv("NEPTUNE_URL"),
+		apiName: os.Getenv("NEPTUNE_APPSYNC_API_NAME"),
+		apiID:   os.Getenv("NEPTUNE_APPSYNC_API_ID"),
+	}
+}
+
+func (e appSyncEndpoint) empty() bool {
+	return firstNonEmpty(e.url, e.apiName, e.apiID) == ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+func intFromEnv(key string) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return parsed, nil
+}
 
-	return cfg
+func durationFromEnv(key string) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return parsed, nil
+}
+
+func boolFromEnv(key string) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return parsed, nil
 }
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `default_env: dev
+environments:
+  dev:
+    aws_profile: dsoadev
+    url: https://dev.appsync-api.us-east-2.amazonaws.com/graphql
+  prod:
+    aws_profile: dsoaprod
+    aws_region: us-east-2
+    api_name: sdr-prod
+    read_only: true
+    output: table
+    schema_file: ~/schemas/prod.json
+    cache_ttl: 10m
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	for _, key := range []string{"NEPTUNE_URL", "NEPTUNE_APPSYNC_API_NAME", "NEPTUNE_APPSYNC_API_ID", "AWS_PROFILE", "AWS_REGION", envEnvName, envConfigPath, envReadOnly, envSchemaFile, envCacheTTL, envCacheMaxBytes} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadConfigUsesDefaultEnvironment(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeTestConfig(t, testConfigFile)
+
+	cfg, err := LoadConfig(LoadOptions{FilePath: path})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Env != "dev" || cfg.AWSProfile != "dsoadev" {
+		t.Fatalf("expected dev environment, got env=%q profile=%q", cfg.Env, cfg.AWSProfile)
+	}
+	if cfg.URL != "https://dev.appsync-api.us-east-2.amazonaws.com/graphql" {
+		t.Fatalf("unexpected URL %q", cfg.URL)
+	}
+	if cfg.ReadOnly != nil {
+		t.Fatalf("expected read_only to be unset, got %v", *cfg.ReadOnly)
+	}
+}
+
+func TestLoadConfigEnvironmentVariablesOverrideFile(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeTestConfig(t, testConfigFile)
+	t.Setenv(envEnvName, "prod")
+	t.Setenv("NEPTUNE_APPSYNC_API_NAME", "sdr-override")
+
+	cfg, err := LoadConfig(LoadOptions{FilePath: path})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Env != "prod" || cfg.AWSRegion != "us-east-2" || cfg.Output != "table" {
+		t.Fatalf("expected prod environment, got %+v", cfg)
+	}
+	if cfg.AppSyncAPIName != "sdr-override" {
+		t.Fatalf("expected env var to override api_name, got %q", cfg.AppSyncAPIName)
+	}
+	if cfg.ReadOnly == nil || !*cfg.ReadOnly {
+		t.Fatalf("expected read_only to be true")
+	}
+	if want := filepath.Join(os.Getenv("HOME"), "schemas", "prod.json"); cfg.SchemaFile != want {
+		t.Fatalf("expected schema_file %q, got %q", want, cfg.SchemaFile)
+	}
+
+	if cfg.CacheTTL != 10*time.Minute {
+		t.Fatalf("expected cache_ttl of 10m, got %v", cfg.CacheTTL)
+	}
+
+	t.Setenv(envReadOnly, "false")
+	t.Setenv(envCacheTTL, "0")
+	cfg, err = LoadConfig(LoadOptions{FilePath: path})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.ReadOnly == nil || *cfg.ReadOnly {
+		t.Fatalf("expected NQ_READ_ONLY to override read_only")
+	}
+	if cfg.CacheTTL != 0 {
+		t.Fatalf("expected NQ_CACHE_TTL to override cache_ttl, got %v", cfg.CacheTTL)
+	}
+
+	t.Setenv(envSchemaFile, "/etc/nq/schema.json")
+	cfg, err = LoadConfig(LoadOptions{FilePath: path})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.SchemaFile != "/etc/nq/schema.json" {
+		t.Fatalf("expected NQ_SCHEMA_FILE to override schema_file, got %q", cfg.SchemaFile)
+	}
+
+	cfg, err = LoadConfig(LoadOptions{FilePath: path, Env: "dev"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Env != "dev" {
+		t.Fatalf("expected explicit env to win over NQ_ENV, got %q", cfg.Env)
+	}
+}
+
+func TestLoadConfigNamedEnvironmentOverridesEnvironmentVariables(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeTestConfig(t, testConfigFile)
+	t.Setenv("NEPTUNE_URL", "https://other.appsync-api.us-east-1.amazonaws.com/graphql")
+	t.Setenv("NEPTUNE_APPSYNC_API_NAME", "sdr-other")
+	t.Setenv("AWS_PROFILE", "other")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv(envReadOnly, "false")
+	t.Setenv(envCacheTTL, "1m")
+
+	cfg, err := LoadConfig(LoadOptions{FilePath: path, Env: "prod"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.AppSyncAPIName != "sdr-prod" || cfg.AWSProfile != "dsoaprod" || cfg.AWSRegion != "us-east-2" {
+		t.Fatalf("expected the prod environment to win, got %+v", cfg)
+	}
+	if cfg.ReadOnly == nil || !*cfg.ReadOnly || cfg.CacheTTL != 10*time.Minute {
+		t.Fatalf("expected prod read_only and cache_ttl, got %+v", cfg)
+	}
+	if cfg.URL != "" {
+		t.Fatalf("expected NEPTUNE_URL to be ignored when prod selects its endpoint by api_name, got %q", cfg.URL)
+	}
+
+	// The default environment yields to environment variables.
+	cfg, err = LoadConfig(LoadOptions{FilePath: path})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Env != "dev" || cfg.AWSProfile != "other" || cfg.AWSRegion != "us-east-1" {
+		t.Fatalf("expected environment variables to win over default_env, got %+v", cfg)
+	}
+	if cfg.URL != "https://other.appsync-api.us-east-1.amazonaws.com/graphql" || cfg.AppSyncAPIName != "sdr-other" {
+		t.Fatalf("expected the endpoint from environment variables, got url=%q api_name=%q", cfg.URL, cfg.AppSyncAPIName)
+	}
+}
+
+func TestLoadConfigEndpointComesFromOneSource(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeTestConfig(t, testConfigFile)
+	t.Setenv("NEPTUNE_URL", "https://other.appsync-api.us-east-1.amazonaws.com/graphql")
+
+	// prod sets only api_name; the exported NEPTUNE_URL must not bypass
+	// discovery of prod's API.
+	cfg, err := LoadConfig(LoadOptions{FilePath: path, Env: "prod"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.URL != "" || cfg.AppSyncAPIName != "sdr-prod" {
+		t.Fatalf("expected only prod's api_name, got url=%q api_name=%q", cfg.URL, cfg.AppSyncAPIName)
+	}
+
+	// The reverse: environment variables beat NQ_ENV, and prod's api_name
+	// must not be mixed into their endpoint.
+	t.Setenv(envEnvName, "prod")
+	cfg, err = LoadConfig(LoadOptions{FilePath: path})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.URL != "https://other.appsync-api.us-east-1.amazonaws.com/graphql" || cfg.AppSyncAPIName != "" {
+		t.Fatalf("expected only NEPTUNE_URL, got url=%q api_name=%q", cfg.URL, cfg.AppSyncAPIName)
+	}
+}
+
+func TestLoadConfigRejectsUnknownEnvironmentAndFields(t *testing.T) {
+	clearConfigEnv(t)
+
+	if _, err := LoadConfig(LoadOptions{FilePath: writeTestConfig(t, testConfigFile), Env: "qa"}); err == nil {
+		t.Fatalf("expected error for unknown environment")
+	}
+	if _, err := LoadConfig(LoadOptions{FilePath: writeTestConfig(t, "environments:\n  dev:\n    profile: x\n")}); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+	if _, err := LoadConfig(LoadOptions{FilePath: filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Fatalf("expected error for missing explicit config file")
+	}
+}
+
+func TestQueryDirs(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(envSharedQueries, "")
+	path := writeTestConfig(t, testConfigFile+"shared_queries: ~/team/queries\n")
+
+	dir, shared, err := QueryDirs(path)
+	if err != nil {
+		t.Fatalf("QueryDirs: %v", err)
+	}
+	if want := filepath.Join(filepath.Dir(path), "queries"); dir != want {
+		t.Fatalf("expected queries dir %q, got %q", want, dir)
+	}
+	if want := filepath.Join(os.Getenv("HOME"), "team", "queries"); shared != want {
+		t.Fatalf("expected shared dir %q, got %q", want, shared)
+	}
+
+	t.Setenv(envSharedQueries, "/srv/nq-queries")
+	if _, shared, err = QueryDirs(path); err != nil || shared != "/srv/nq-queries" {
+		t.Fatalf("expected $%s to win, got %q (err %v)", envSharedQueries, shared, err)
+	}
+}
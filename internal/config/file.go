@@ -0,0 +1,170 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envConfigPath    = "NQ_CONFIG"
+	envEnvName       = "NQ_ENV"
+	envSharedQueries = "NQ_SHARED_QUERIES"
+)
+
+// File is the on-disk configuration, by default ~/.config/nqcli/config.yaml:
+//
+//	default_env: dev
+//	environments:
+//	  dev:
+//	    aws_profile: dsoadev
+//	    aws_region: us-east-2
+//	  prod:
+//	    aws_profile: dsoaprod
+//	    api_name: sdr-prod
+//	    read_only: true
+//	    output: table
+//	    schema_file: ~/sdr/schema/prod.json
+//	    cache_ttl: 10m
+//	shared_queries: /mnt/team/nq-queries
+type File struct {
+	DefaultEnv   string                  `yaml:"default_env"`
+	Environments map[string]*Environment `yaml:"environments"`
+	// SharedQueries is a team directory of saved queries, read alongside the
+	// personal queries directory next to the config file.
+	SharedQueries string `yaml:"shared_queries"`
+}
+
+// Environment is one named target in the config file. Empty fields fall back
+// to environment variables and defaults.
+type Environment struct {
+	AWSProfile string `yaml:"aws_profile"`
+	AWSRegion  string `yaml:"aws_region"`
+	URL        string `yaml:"url"`
+	APIName    string `yaml:"api_name"`
+	APIID      string `yaml:"api_id"`
+	// ReadOnly is a pointer so an unset value can fall back to the
+	// per-command default.
+	ReadOnly *bool  `yaml:"read_only"`
+	Output   string `yaml:"output"`
+	// SchemaFile is a static schema document that replaces the embedded one.
+	SchemaFile string `yaml:"schema_file"`
+	// CacheTTL turns on the query result cache, for example "10m".
+	CacheTTL      string `yaml:"cache_ttl"`
+	CacheMaxBytes int64  `yaml:"cache_max_bytes"`
+}
+
+// DefaultFilePath returns the config file location: $NQ_CONFIG when set,
+// otherwise nqcli/config.yaml under the user's config directory.
+func DefaultFilePath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv(envConfigPath)); path != "" {
+		return expandPath(path)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve config directory: %w", err)
+	}
+	return filepath.Join(dir, "nqcli", "config.yaml"), nil
+}
+
+// QueryDirs returns the saved query directories for the config file at path
+// (or DefaultFilePath when empty): the personal "queries" directory next to
+// the config file, and the shared directory from $NQ_SHARED_QUERIES or
+// shared_queries, which is empty when neither is set.
+func QueryDirs(path string) (dir, shared string, err error) {
+	file, err := LoadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	if path == "" {
+		if path, err = DefaultFilePath(); err != nil {
+			return "", "", err
+		}
+	} else if path, err = expandPath(path); err != nil {
+		return "", "", err
+	}
+	dir = filepath.Join(filepath.Dir(path), "queries")
+	if sharedDir := firstNonEmpty(os.Getenv(envSharedQueries), file.SharedQueries); sharedDir != "" {
+		if shared, err = expandPath(sharedDir); err != nil {
+			return "", "", fmt.Errorf("invalid shared queries directory %q: %w", sharedDir, err)
+		}
+	}
+	return dir, shared, nil
+}
+
+// LoadFile reads the config file at path, or at DefaultFilePath when path is
+// empty. A missing default file yields an empty File; a missing explicit file
+// is an error.
+func LoadFile(path string) (*File, error) {
+	explicit := path != ""
+	if explicit {
+		expanded, err := expandPath(path)
+		if err != nil {
+			return nil, err
+		}
+		path = expanded
+	} else {
+		resolved, err := DefaultFilePath()
+		if err != nil {
+			return &File{}, nil
+		}
+		path = resolved
+		explicit = os.Getenv(envConfigPath) != ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	return &file, nil
+}
+
+// Environment returns the named environment. An empty name selects
+// $NQ_ENV, then default_env; when neither is set the result is nil.
+func (f *File) Environment(name string) (string, *Environment, error) {
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv(envEnvName))
+	}
+	if name == "" {
+		name = f.DefaultEnv
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+
+	env, ok := f.Environments[name]
+	if !ok || env == nil {
+		if len(f.Environments) == 0 {
+			return "", nil, fmt.Errorf("environment %q not found: no environments are configured", name)
+		}
+		return "", nil, fmt.Errorf("environment %q not found; available: %s", name, strings.Join(f.EnvironmentNames(), ", "))
+	}
+	return name, env, nil
+}
+
+// EnvironmentNames lists the configured environments in sorted order.
+func (f *File) EnvironmentNames() []string {
+	names := make([]string, 0, len(f.Environments))
+	for name := range f.Environments {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -1,10 +1,13 @@
 package e2e
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/ankit-lilly/nqcli/internal/safety"
 )
 
 // SDRClient wraps the gq.Client to provide SDR-specific GraphQL operations.
@@ -17,7 +20,7 @@ func NewSDRClient(gql *neptune.Client, verbose bool) *SDRClient {
 	return &SDRClient{gql: gql, verbose: verbose}
 }
 
-func (c *SDRClient) SubmitData(payload SdrPayload) (*SubmitResponse, error) {
+func (c *SDRClient) SubmitData(ctx context.Context, payload SdrPayload) (*SubmitResponse, error) {
 	const mutation = `mutation SubmitData($input: SdrPayload!) {
 		submitData(input: $input) {
 			message
@@ -26,7 +29,7 @@ func (c *SDRClient) SubmitData(payload SdrPayload) (*SubmitResponse, error) {
 	}`
 
 	vars := map[string]any{"input": payload}
-	result, err := c.execute(mutation, vars)
+	result, err := c.execute(ctx, mutation, vars)
 	if err != nil {
 		return nil, fmt.Errorf("submitData: %w", err)
 	}
@@ -43,7 +46,7 @@ func (c *SDRClient) SubmitData(payload SdrPayload) (*SubmitResponse, error) {
 	return &resp, nil
 }
 
-func (c *SDRClient) GetTrialHistory(trialAlias string) (*TrialVersionHistory, error) {
+func (c *SDRClient) GetTrialHistory(ctx context.Context, trialAlias string) (*TrialVersionHistory, error) {
 	const query = `query TrialHistory($trialAlias: String!) {
 		trialHistory(trialAlias: $trialAlias) {
 			status
@@ -71,7 +74,7 @@ func (c *SDRClient) GetTrialHistory(trialAlias string) (*TrialVersionHistory, er
 	}`
 
 	vars := map[string]any{"trialAlias": trialAlias}
-	result, err := c.execute(query, vars)
+	result, err := c.execute(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("trialHistory: %w", err)
 	}
@@ -88,13 +91,13 @@ func (c *SDRClient) GetTrialHistory(trialAlias string) (*TrialVersionHistory, er
 	return &resp, nil
 }
 
-func (c *SDRClient) GetTrials() ([]Trial, error) {
+func (c *SDRClient) GetTrials(ctx context.Context) ([]Trial, error) {
 	const query = `query { trials {
 		trialAlias dsVersion dsVersionTimestamp sdrVersion
 		sdrIngestionTimestamp status therapeuticAreas studyPhase studyType
 	}}`
 
-	result, err := c.execute(query, nil)
+	result, err := c.execute(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("trials: %w", err)
 	}
@@ -111,13 +114,13 @@ func (c *SDRClient) GetTrials() ([]Trial, error) {
 	return resp, nil
 }
 
-func (c *SDRClient) GetGraphSummary() (*GraphSummary, error) {
+func (c *SDRClient) GetGraphSummary(ctx context.Context) (*GraphSummary, error) {
 	const query = `query { graphSummary {
 		totalNodes totalEdges totalNodeTypes
 		nodes { label totalCount }
 	}}`
 
-	result, err := c.execute(query, nil)
+	result, err := c.execute(ctx, query, nil)
 	if err != nil {
 		return nil, fmt.Errorf("graphSummary: %w", err)
 	}
@@ -134,30 +137,23 @@ func (c *SDRClient) GetGraphSummary() (*GraphSummary, error) {
 	return &resp, nil
 }
 
-func (c *SDRClient) ExecuteGremlin(query string) (json.RawMessage, error) {
-	raw, err := c.gql.ExecuteQuery(query, "gremlin")
+func (c *SDRClient) ExecuteGremlin(ctx context.Context, query string) (*result.QueryResult, error) {
+	raw, err := c.gql.ExecuteQueryContext(ctx, query, "gremlin", !safety.IsMutating(query, "gremlin"))
 	if err != nil {
 		return nil, fmt.Errorf("gremlin: %w", err)
 	}
 
 	// The response is: {"data":{"executeQuery":"<escaped JSON>"}}
-	var envelope struct {
-		Data struct {
-			ExecuteQuery string `json:"executeQuery"`
-		} `json:"data"`
-		Errors []graphQLError `json:"errors"`
-	}
-	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+	// GraphQL errors are already surfaced by the client as *gq.ResponseError.
+	res, err := result.FromResponse(raw, result.Options{})
+	if err != nil {
 		return nil, fmt.Errorf("gremlin unmarshal envelope: %w", err)
 	}
-	if len(envelope.Errors) > 0 {
-		return nil, fmt.Errorf("gremlin errors: %s", envelope.Errors[0].Message)
-	}
-
-	return json.RawMessage(envelope.Data.ExecuteQuery), nil
+	res.Type, res.Query = "gremlin", query
+	return res, nil
 }
 
-func (c *SDRClient) DeleteAllVersions(trialAlias string) (*DeleteResponse, error) {
+func (c *SDRClient) DeleteAllVersions(ctx context.Context, trialAlias string) (*DeleteResponse, error) {
 	const mutation = `mutation DeleteAll($trialAlias: String!) {
 		deleteAllVersionsByTrialAlias(trialAlias: $trialAlias) {
 			success
@@ -166,7 +162,7 @@ func (c *SDRClient) DeleteAllVersions(trialAlias string) (*DeleteResponse, error
 	}`
 
 	vars := map[string]any{"trialAlias": trialAlias}
-	result, err := c.execute(mutation, vars)
+	result, err := c.execute(ctx, mutation, vars)
 	if err != nil {
 		return nil, fmt.Errorf("deleteAll: %w", err)
 	}
@@ -183,7 +179,7 @@ func (c *SDRClient) DeleteAllVersions(trialAlias string) (*DeleteResponse, error
 	return &resp, nil
 }
 
-func (c *SDRClient) DeleteSingleVersion(trialAlias string, versionID string) (*DeleteResponse, error) {
+func (c *SDRClient) DeleteSingleVersion(ctx context.Context, trialAlias string, versionID string) (*DeleteResponse, error) {
 	const mutation = `mutation DeleteVersion($trialAlias: String!, $versionIdentifier: ID!) {
 		deleteSingleVersion(trialAlias: $trialAlias, versionIdentifier: $versionIdentifier) {
 			success
@@ -192,7 +188,7 @@ func (c *SDRClient) DeleteSingleVersion(trialAlias string, versionID string) (*D
 	}`
 
 	vars := map[string]any{"trialAlias": trialAlias, "versionIdentifier": versionID}
-	result, err := c.execute(mutation, vars)
+	result, err := c.execute(ctx, mutation, vars)
 	if err != nil {
 		return nil, fmt.Errorf("deleteVersion: %w", err)
 	}
@@ -210,35 +206,27 @@ func (c *SDRClient) DeleteSingleVersion(trialAlias string, versionID string) (*D
 }
 
 // execute sends a GraphQL operation and returns the parsed data map.
-func (c *SDRClient) execute(query string, variables any) (map[string]json.RawMessage, error) {
-	raw, err := c.gql.ExecuteGraphQL(query, variables)
-	if err != nil {
-		return nil, err
-	}
+func (c *SDRClient) execute(ctx context.Context, query string, variables any) (map[string]json.RawMessage, error) {
+	raw, err := c.gql.ExecuteGraphQLContext(ctx, query, variables)
 
-	if c.verbose {
+	if c.verbose && raw != "" {
 		fmt.Printf("  [graphql] response: %s\n", raw)
 	}
 
+	if err != nil {
+		return nil, err
+	}
+
 	var envelope struct {
-		Data   map[string]json.RawMessage `json:"data"`
-		Errors []graphQLError             `json:"errors"`
+		Data map[string]json.RawMessage `json:"data"`
 	}
 	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if len(envelope.Errors) > 0 {
-		return nil, fmt.Errorf("graphql error: %s", envelope.Errors[0].Message)
-	}
-
 	return envelope.Data, nil
 }
 
-type graphQLError struct {
-	Message string `json:"message"`
-}
-
 // extractField extracts a single field from the data map.
 func extractField(data map[string]json.RawMessage, field string) (json.RawMessage, error) {
 	raw, ok := data[field]
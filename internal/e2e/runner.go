@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -14,8 +15,13 @@ type RunnerConfig struct {
 	CleanupOnly bool          // Only clean up test data, don't run scenarios.
 }
 
-// Run executes the integration test scenarios and returns a report.
-func Run(client *SDRClient, cfg RunnerConfig) Report {
+// cleanupTimeout bounds the best-effort cleanup that still runs after the
+// parent context has been cancelled.
+const cleanupTimeout = 30 * time.Second
+
+// Run executes the integration test scenarios and returns a report. Scenarios
+// not yet started when ctx is cancelled are reported as skipped.
+func Run(ctx context.Context, client *SDRClient, cfg RunnerConfig) Report {
 	start := time.Now()
 
 	scenarios := AllScenarios(cfg.TrialPrefix)
@@ -26,7 +32,7 @@ func Run(client *SDRClient, cfg RunnerConfig) Report {
 	}
 
 	if cfg.CleanupOnly {
-		return runCleanupOnly(client, scenarios)
+		return runCleanupOnly(ctx, client, scenarios)
 	}
 
 	// Filter to a single scenario if requested.
@@ -54,7 +60,11 @@ func Run(client *SDRClient, cfg RunnerConfig) Report {
 
 	var results []Result
 	for _, scenario := range scenarios {
-		result := runScenario(client, scenario, pollCfg, cfg.Verbose)
+		if ctx.Err() != nil {
+			results = append(results, Result{Name: scenario.Name, Skipped: true})
+			continue
+		}
+		result := runScenario(ctx, client, scenario, pollCfg, cfg.Verbose)
 		results = append(results, result)
 	}
 
@@ -64,21 +74,21 @@ func Run(client *SDRClient, cfg RunnerConfig) Report {
 	}
 }
 
-func runScenario(client *SDRClient, scenario Scenario, pollCfg PollConfig, verbose bool) Result {
+func runScenario(ctx context.Context, client *SDRClient, scenario Scenario, pollCfg PollConfig, verbose bool) Result {
 	fmt.Printf("  ▶ %s: %s\n", scenario.Name, scenario.Description)
 
 	// Pre-cleanup: remove any leftover data from previous failed runs.
 	if scenario.TrialAlias != "" {
-		cleanup(client, scenario.TrialAlias, verbose)
+		cleanup(ctx, client, scenario.TrialAlias, verbose)
 	}
 
 	start := time.Now()
-	err := scenario.Run(client, pollCfg)
+	err := scenario.Run(ctx, client, pollCfg)
 	duration := time.Since(start)
 
 	// Post-cleanup.
 	if scenario.TrialAlias != "" {
-		cleanup(client, scenario.TrialAlias, verbose)
+		cleanup(ctx, client, scenario.TrialAlias, verbose)
 	}
 
 	if err != nil {
@@ -90,7 +100,7 @@ func runScenario(client *SDRClient, scenario Scenario, pollCfg PollConfig, verbo
 	return Result{Name: scenario.Name, Passed: true, Duration: duration}
 }
 
-func runCleanupOnly(client *SDRClient, scenarios []Scenario) Report {
+func runCleanupOnly(ctx context.Context, client *SDRClient, scenarios []Scenario) Report {
 	start := time.Now()
 	fmt.Println("  Cleaning up test data...")
 
@@ -99,7 +109,7 @@ func runCleanupOnly(client *SDRClient, scenarios []Scenario) Report {
 			continue
 		}
 		fmt.Printf("    cleaning %s...", s.TrialAlias)
-		cleanup(client, s.TrialAlias, false)
+		cleanup(ctx, client, s.TrialAlias, false)
 		fmt.Println(" done")
 	}
 
@@ -107,9 +117,13 @@ func runCleanupOnly(client *SDRClient, scenarios []Scenario) Report {
 	return Report{Duration: time.Since(start)}
 }
 
-func cleanup(client *SDRClient, trialAlias string, verbose bool) {
+// cleanup deletes test data for trialAlias. It detaches from ctx cancellation
+// so an interrupted run still removes what it created.
+func cleanup(ctx context.Context, client *SDRClient, trialAlias string, verbose bool) {
 	if verbose {
 		fmt.Printf("    [cleanup] deleting %s\n", trialAlias)
 	}
-	_, _ = client.DeleteAllVersions(trialAlias)
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	_, _ = client.DeleteAllVersions(cleanupCtx, trialAlias)
 }
@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -10,7 +11,7 @@ type Scenario struct {
 	Name        string
 	Description string
 	TrialAlias  string
-	Run         func(client *SDRClient, cfg PollConfig) error
+	Run         func(ctx context.Context, client *SDRClient, cfg PollConfig) error
 }
 
 // AllScenarios returns the full list of integration test scenarios.
@@ -33,10 +34,10 @@ func submitAndVerify(prefix string) Scenario {
 		Name:        "submit-and-verify",
 		Description: "Submit a minimal payload and verify it lands in Neptune",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			payload := BuildMinimalPayload(alias)
 
-			resp, err := client.SubmitData(payload)
+			resp, err := client.SubmitData(ctx, payload)
 			if err != nil {
 				return fmt.Errorf("submit failed: %w", err)
 			}
@@ -44,7 +45,7 @@ func submitAndVerify(prefix string) Scenario {
 				return fmt.Errorf("expected isValid=true, got message: %s", resp.Message)
 			}
 
-			history, err := WaitForVersion(client, alias, 1, cfg)
+			history, err := WaitForVersion(ctx, client, alias, 1, cfg)
 			if err != nil {
 				return err
 			}
@@ -52,10 +53,10 @@ func submitAndVerify(prefix string) Scenario {
 				return fmt.Errorf("expected status SUCCESS, got %s", history.TrialVersionHistory[0].Status)
 			}
 
-			if err := VerifyStudyExists(client, alias); err != nil {
+			if err := VerifyStudyExists(ctx, client, alias); err != nil {
 				return err
 			}
-			if err := VerifyVersionCount(client, alias, 1); err != nil {
+			if err := VerifyVersionCount(ctx, client, alias, 1); err != nil {
 				return err
 			}
 
@@ -69,10 +70,10 @@ func invalidPayloadRejection(prefix string) Scenario {
 		Name:        "invalid-payload-rejection",
 		Description: "Submit a payload with invalid trialAlias and expect sync rejection",
 		TrialAlias:  "", // No cleanup needed; payload is rejected synchronously.
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			payload := BuildInvalidPayload()
 
-			_, err := client.SubmitData(payload)
+			_, err := client.SubmitData(ctx, payload)
 			if err == nil {
 				return fmt.Errorf("expected error for invalid payload, but submission succeeded")
 			}
@@ -88,10 +89,10 @@ func versionIncrement(prefix string) Scenario {
 		Name:        "version-increment",
 		Description: "Submit the same trialAlias twice and verify version increments",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			// First submission.
 			payload1 := BuildMinimalPayload(alias)
-			resp1, err := client.SubmitData(payload1)
+			resp1, err := client.SubmitData(ctx, payload1)
 			if err != nil {
 				return fmt.Errorf("first submit failed: %w", err)
 			}
@@ -99,17 +100,19 @@ func versionIncrement(prefix string) Scenario {
 				return fmt.Errorf("first submit not valid: %s", resp1.Message)
 			}
 
-			if _, err := WaitForVersion(client, alias, 1, cfg); err != nil {
+			if _, err := WaitForVersion(ctx, client, alias, 1, cfg); err != nil {
 				return fmt.Errorf("waiting for first version: %w", err)
 			}
 
 			// Brief pause before second submission.
-			time.Sleep(2 * time.Second)
+			if err := sleepContext(ctx, 2*time.Second); err != nil {
+				return err
+			}
 
 			// Second submission.
 			payload2 := BuildMinimalPayload(alias)
 			payload2.ID = fmt.Sprintf("e2e-test-v2-%d", time.Now().UnixMilli())
-			resp2, err := client.SubmitData(payload2)
+			resp2, err := client.SubmitData(ctx, payload2)
 			if err != nil {
 				return fmt.Errorf("second submit failed: %w", err)
 			}
@@ -117,11 +120,11 @@ func versionIncrement(prefix string) Scenario {
 				return fmt.Errorf("second submit not valid: %s", resp2.Message)
 			}
 
-			if _, err := WaitForVersion(client, alias, 2, cfg); err != nil {
+			if _, err := WaitForVersion(ctx, client, alias, 2, cfg); err != nil {
 				return fmt.Errorf("waiting for second version: %w", err)
 			}
 
-			if err := VerifyVersionCount(client, alias, 2); err != nil {
+			if err := VerifyVersionCount(ctx, client, alias, 2); err != nil {
 				return err
 			}
 
@@ -136,9 +139,9 @@ func deleteSingleVersionScenario(prefix string) Scenario {
 		Name:        "delete-single-version",
 		Description: "Submit, then delete single version and verify it's removed",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			payload := BuildMinimalPayload(alias)
-			resp, err := client.SubmitData(payload)
+			resp, err := client.SubmitData(ctx, payload)
 			if err != nil {
 				return fmt.Errorf("submit failed: %w", err)
 			}
@@ -146,13 +149,13 @@ func deleteSingleVersionScenario(prefix string) Scenario {
 				return fmt.Errorf("submit not valid: %s", resp.Message)
 			}
 
-			history, err := WaitForVersion(client, alias, 1, cfg)
+			history, err := WaitForVersion(ctx, client, alias, 1, cfg)
 			if err != nil {
 				return err
 			}
 
 			versionID := history.TrialVersionHistory[0].SDRVersion
-			delResp, err := client.DeleteSingleVersion(alias, versionID)
+			delResp, err := client.DeleteSingleVersion(ctx, alias, versionID)
 			if err != nil {
 				return fmt.Errorf("deleteSingleVersion failed: %w", err)
 			}
@@ -171,9 +174,9 @@ func deleteAllVersionsScenario(prefix string) Scenario {
 		Name:        "delete-all-versions",
 		Description: "Submit, then delete all versions and verify study is gone",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			payload := BuildMinimalPayload(alias)
-			resp, err := client.SubmitData(payload)
+			resp, err := client.SubmitData(ctx, payload)
 			if err != nil {
 				return fmt.Errorf("submit failed: %w", err)
 			}
@@ -181,11 +184,11 @@ func deleteAllVersionsScenario(prefix string) Scenario {
 				return fmt.Errorf("submit not valid: %s", resp.Message)
 			}
 
-			if _, err := WaitForVersion(client, alias, 1, cfg); err != nil {
+			if _, err := WaitForVersion(ctx, client, alias, 1, cfg); err != nil {
 				return err
 			}
 
-			delResp, err := client.DeleteAllVersions(alias)
+			delResp, err := client.DeleteAllVersions(ctx, alias)
 			if err != nil {
 				return fmt.Errorf("deleteAllVersions failed: %w", err)
 			}
@@ -193,7 +196,7 @@ func deleteAllVersionsScenario(prefix string) Scenario {
 				return fmt.Errorf("deleteAllVersions returned success=false: %s", delResp.Message)
 			}
 
-			if err := WaitForCleanup(client, alias, 15*time.Second); err != nil {
+			if err := WaitForCleanup(ctx, client, alias, 15*time.Second); err != nil {
 				return err
 			}
 
@@ -208,9 +211,9 @@ func graphStructure(prefix string) Scenario {
 		Name:        "graph-structure",
 		Description: "Submit full payload and verify graph node types exist",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			payload := BuildFullPayload(alias)
-			resp, err := client.SubmitData(payload)
+			resp, err := client.SubmitData(ctx, payload)
 			if err != nil {
 				return fmt.Errorf("submit failed: %w", err)
 			}
@@ -218,7 +221,7 @@ func graphStructure(prefix string) Scenario {
 				return fmt.Errorf("submit not valid: %s", resp.Message)
 			}
 
-			history, err := WaitForVersion(client, alias, 1, cfg)
+			history, err := WaitForVersion(ctx, client, alias, 1, cfg)
 			if err != nil {
 				return err
 			}
@@ -238,7 +241,7 @@ func graphStructure(prefix string) Scenario {
 			}
 
 			for _, check := range checks {
-				if err := VerifyNodesByLabel(client, alias, check.label, check.minCount); err != nil {
+				if err := VerifyNodesByLabel(ctx, client, alias, check.label, check.minCount); err != nil {
 					return err
 				}
 			}
@@ -254,9 +257,9 @@ func trialsQuery(prefix string) Scenario {
 		Name:        "trials-query",
 		Description: "Submit and verify the trial appears in the trials query",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
 			payload := BuildMinimalPayload(alias)
-			resp, err := client.SubmitData(payload)
+			resp, err := client.SubmitData(ctx, payload)
 			if err != nil {
 				return fmt.Errorf("submit failed: %w", err)
 			}
@@ -264,11 +267,11 @@ func trialsQuery(prefix string) Scenario {
 				return fmt.Errorf("submit not valid: %s", resp.Message)
 			}
 
-			if _, err := WaitForVersion(client, alias, 1, cfg); err != nil {
+			if _, err := WaitForVersion(ctx, client, alias, 1, cfg); err != nil {
 				return err
 			}
 
-			trials, err := client.GetTrials()
+			trials, err := client.GetTrials(ctx)
 			if err != nil {
 				return fmt.Errorf("trials query failed: %w", err)
 			}
@@ -289,14 +292,14 @@ func graphSummaryScenario(prefix string) Scenario {
 		Name:        "graph-summary",
 		Description: "Capture graph summary before and after submit, verify counts increased",
 		TrialAlias:  alias,
-		Run: func(client *SDRClient, cfg PollConfig) error {
-			before, err := client.GetGraphSummary()
+		Run: func(ctx context.Context, client *SDRClient, cfg PollConfig) error {
+			before, err := client.GetGraphSummary(ctx)
 			if err != nil {
 				return fmt.Errorf("graphSummary (before): %w", err)
 			}
 
 			payload := BuildMinimalPayload(alias)
-			resp, err := client.SubmitData(payload)
+			resp, err := client.SubmitData(ctx, payload)
 			if err != nil {
 				return fmt.Errorf("submit failed: %w", err)
 			}
@@ -304,11 +307,11 @@ func graphSummaryScenario(prefix string) Scenario {
 				return fmt.Errorf("submit not valid: %s", resp.Message)
 			}
 
-			if _, err := WaitForVersion(client, alias, 1, cfg); err != nil {
+			if _, err := WaitForVersion(ctx, client, alias, 1, cfg); err != nil {
 				return err
 			}
 
-			after, err := client.GetGraphSummary()
+			after, err := client.GetGraphSummary(ctx)
 			if err != nil {
 				return fmt.Errorf("graphSummary (after): %w", err)
 			}
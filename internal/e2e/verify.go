@@ -1,10 +1,13 @@
 package e2e
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/params"
+	"github.com/ankit-lilly/nqcli/internal/result"
 )
 
 // PollConfig controls polling behavior for async operations.
@@ -22,11 +25,11 @@ func DefaultPollConfig() PollConfig {
 
 // WaitForVersion polls trialHistory until at least expectedCount versions appear
 // or the timeout is reached.
-func WaitForVersion(client *SDRClient, trialAlias string, expectedCount int, cfg PollConfig) (*TrialVersionHistory, error) {
+func WaitForVersion(ctx context.Context, client *SDRClient, trialAlias string, expectedCount int, cfg PollConfig) (*TrialVersionHistory, error) {
 	deadline := time.Now().Add(cfg.Timeout)
 
 	for time.Now().Before(deadline) {
-		history, err := client.GetTrialHistory(trialAlias)
+		history, err := client.GetTrialHistory(ctx, trialAlias)
 		if err != nil {
 			// Trial not found yet is expected during processing.
 			if !isNotFoundError(err) {
@@ -36,34 +39,38 @@ func WaitForVersion(client *SDRClient, trialAlias string, expectedCount int, cfg
 			return history, nil
 		}
 
-		time.Sleep(cfg.Interval)
+		if err := sleepContext(ctx, cfg.Interval); err != nil {
+			return nil, err
+		}
 	}
 
 	return nil, fmt.Errorf("timed out after %s waiting for %d version(s) of %s", cfg.Timeout, expectedCount, trialAlias)
 }
 
 // WaitForCleanup polls until a study no longer exists in Neptune.
-func WaitForCleanup(client *SDRClient, trialAlias string, timeout time.Duration) error {
+func WaitForCleanup(ctx context.Context, client *SDRClient, trialAlias string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
-	query := fmt.Sprintf(`g.V().has('Study','name','%s').count()`, trialAlias)
+	query := fmt.Sprintf(`g.V().has('Study','name',%s).count()`, params.QuoteGremlin(trialAlias))
 
 	for time.Now().Before(deadline) {
-		count, err := gremlinCount(client, query)
+		count, err := gremlinCount(ctx, client, query)
 		if err != nil {
 			return fmt.Errorf("cleanup check failed: %w", err)
 		}
 		if count == 0 {
 			return nil
 		}
-		time.Sleep(2 * time.Second)
+		if err := sleepContext(ctx, 2*time.Second); err != nil {
+			return err
+		}
 	}
 	return fmt.Errorf("timed out waiting for cleanup of %s", trialAlias)
 }
 
 // VerifyStudyExists checks that exactly one Study node exists for the given trialAlias.
-func VerifyStudyExists(client *SDRClient, trialAlias string) error {
-	query := fmt.Sprintf(`g.V().has('Study','name','%s').count()`, trialAlias)
-	count, err := gremlinCount(client, query)
+func VerifyStudyExists(ctx context.Context, client *SDRClient, trialAlias string) error {
+	query := fmt.Sprintf(`g.V().has('Study','name',%s).count()`, params.QuoteGremlin(trialAlias))
+	count, err := gremlinCount(ctx, client, query)
 	if err != nil {
 		return fmt.Errorf("verifyStudyExists: %w", err)
 	}
@@ -74,9 +81,9 @@ func VerifyStudyExists(client *SDRClient, trialAlias string) error {
 }
 
 // VerifyStudyGone checks that no Study node exists for the given trialAlias.
-func VerifyStudyGone(client *SDRClient, trialAlias string) error {
-	query := fmt.Sprintf(`g.V().has('Study','name','%s').count()`, trialAlias)
-	count, err := gremlinCount(client, query)
+func VerifyStudyGone(ctx context.Context, client *SDRClient, trialAlias string) error {
+	query := fmt.Sprintf(`g.V().has('Study','name',%s).count()`, params.QuoteGremlin(trialAlias))
+	count, err := gremlinCount(ctx, client, query)
 	if err != nil {
 		return fmt.Errorf("verifyStudyGone: %w", err)
 	}
@@ -87,9 +94,9 @@ func VerifyStudyGone(client *SDRClient, trialAlias string) error {
 }
 
 // VerifyVersionCount checks that the expected number of StudyVersion nodes exist.
-func VerifyVersionCount(client *SDRClient, trialAlias string, expected int) error {
-	query := fmt.Sprintf(`g.V().has('Study','name','%s').out('has_version').hasLabel('StudyVersion').count()`, trialAlias)
-	count, err := gremlinCount(client, query)
+func VerifyVersionCount(ctx context.Context, client *SDRClient, trialAlias string, expected int) error {
+	query := fmt.Sprintf(`g.V().has('Study','name',%s).out('has_version').hasLabel('StudyVersion').count()`, params.QuoteGremlin(trialAlias))
+	count, err := gremlinCount(ctx, client, query)
 	if err != nil {
 		return fmt.Errorf("verifyVersionCount: %w", err)
 	}
@@ -101,12 +108,12 @@ func VerifyVersionCount(client *SDRClient, trialAlias string, expected int) erro
 
 // VerifyNodesByLabel checks that at least minCount nodes of the given label
 // exist in the subgraph reachable from the study node.
-func VerifyNodesByLabel(client *SDRClient, trialAlias string, label string, minCount int) error {
+func VerifyNodesByLabel(ctx context.Context, client *SDRClient, trialAlias string, label string, minCount int) error {
 	query := fmt.Sprintf(
-		`g.V().has('Study','name','%s').repeat(out()).emit().hasLabel('%s').count()`,
-		trialAlias, label,
+		`g.V().has('Study','name',%s).repeat(out()).emit().hasLabel(%s).count()`,
+		params.QuoteGremlin(trialAlias), params.QuoteGremlin(label),
 	)
-	count, err := gremlinCount(client, query)
+	count, err := gremlinCount(ctx, client, query)
 	if err != nil {
 		return fmt.Errorf("verifyNodesByLabel(%s): %w", label, err)
 	}
@@ -117,42 +124,36 @@ func VerifyNodesByLabel(client *SDRClient, trialAlias string, label string, minC
 }
 
 // gremlinCount executes a Gremlin count query and returns the integer result.
-func gremlinCount(client *SDRClient, query string) (int, error) {
-	raw, err := client.ExecuteGremlin(query)
+func gremlinCount(ctx context.Context, client *SDRClient, query string) (int, error) {
+	res, err := client.ExecuteGremlin(ctx, query)
 	if err != nil {
 		return 0, err
 	}
 
-	// The response from Neptune count queries can be:
-	// - A JSON number: 5
-	// - A JSON array: [5]
-	// - A JSON string containing a number: "5"
-	// - A JSON object with a result field: {"result": [5]}
-	var count int
-
-	// Try parsing as a plain number first.
-	if err := json.Unmarshal(raw, &count); err == nil {
-		return count, nil
+	// The result of Neptune count queries can be a number, a one-element
+	// list or an object with a result field holding one of those.
+	value := res.Value
+	if obj, ok := value.(map[string]any); ok {
+		value = obj["result"]
 	}
-
-	// Try parsing as an array of numbers.
-	var arr []int
-	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) > 0 {
-		return arr[0], nil
+	count, err := result.Int64(value)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse gremlin count from response: %s", res.JSON())
 	}
+	return int(count), nil
+}
 
-	// Try parsing as a nested result object.
-	var obj map[string]json.RawMessage
-	if err := json.Unmarshal(raw, &obj); err == nil {
-		if result, ok := obj["result"]; ok {
-			var resultArr []int
-			if err := json.Unmarshal(result, &resultArr); err == nil && len(resultArr) > 0 {
-				return resultArr[0], nil
-			}
-		}
-	}
+// sleepContext pauses for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	return 0, fmt.Errorf("unable to parse gremlin count from response: %s", string(raw))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func isNotFoundError(err error) bool {
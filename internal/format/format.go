@@ -0,0 +1,304 @@
+// Package format renders decoded query results as JSON, NDJSON, YAML or
+// tabular text (aligned tables, CSV and TSV).
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"gopkg.in/yaml.v3"
+)
+
+// Format names an output representation for query results.
+type Format string
+
+const (
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	Table  Format = "table"
+	CSV    Format = "csv"
+	TSV    Format = "tsv"
+	YAML   Format = "yaml"
+	// Raw is the untouched AppSync response. It is handled by callers because
+	// it bypasses decoding entirely.
+	Raw Format = "raw"
+)
+
+// valueColumn names the single column used for scalar results.
+const valueColumn = "value"
+
+// Names lists every supported format, in the order shown in help text.
+func Names() []string {
+	return []string{string(JSON), string(NDJSON), string(Table), string(CSV), string(TSV), string(YAML), string(Raw)}
+}
+
+// Parse validates a user-supplied format name.
+func Parse(name string) (Format, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	if normalized == "" {
+		return JSON, nil
+	}
+	if !slices.Contains(Names(), normalized) {
+		return "", fmt.Errorf("invalid output format %q. Must be one of: %s", name, strings.Join(Names(), ", "))
+	}
+	return Format(normalized), nil
+}
+
+// Options tweaks rendering.
+type Options struct {
+	// Styled renders tables with lipgloss borders and colours; intended for
+	// terminals. When false tables are plain, space-aligned text.
+	Styled bool
+}
+
+// Write renders value to w in format f.
+func Write(w io.Writer, value any, f Format, opts Options) error {
+	switch f {
+	case JSON, "":
+		return writeJSON(w, value)
+	case NDJSON:
+		return writeNDJSON(w, value)
+	case YAML:
+		return writeYAML(w, value)
+	case Table:
+		columns, rows := Flatten(value)
+		if opts.Styled {
+			return writeStyledTable(w, columns, rows)
+		}
+		return writePlainTable(w, columns, rows)
+	case CSV:
+		columns, rows := Flatten(value)
+		return writeDelimited(w, ',', columns, rows)
+	case TSV:
+		columns, rows := Flatten(value)
+		return writeDelimited(w, '\t', columns, rows)
+	default:
+		return fmt.Errorf("format %q cannot render decoded values", f)
+	}
+}
+
+// Flatten turns a result into a header row and string cells. Lists of maps
+// (valueMap, elementMap, project) become one row per map with the union of
+// keys as columns; scalars land in a single "value" column. Single-element
+// lists, as produced by valueMap, are unwrapped; other nested values are
+// rendered as compact JSON.
+func Flatten(value any) ([]string, [][]string) {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+
+	var (
+		columns  []string
+		seen     = map[string]bool{}
+		hasValue bool
+	)
+	for _, item := range items {
+		m, isMap := item.(map[string]any)
+		if !isMap {
+			hasValue = true
+			continue
+		}
+		for _, key := range orderedKeys(m) {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	if hasValue && !seen[valueColumn] {
+		columns = append(columns, valueColumn)
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(columns))
+		if m, isMap := item.(map[string]any); isMap {
+			for i, column := range columns {
+				if cell, exists := m[column]; exists {
+					row[i] = Cell(cell)
+				}
+			}
+		} else {
+			row[slices.Index(columns, valueColumn)] = Cell(item)
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+// Cell renders a single value for tabular output.
+func Cell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool, float64, int, int64:
+		return fmt.Sprint(v)
+	case []any:
+		if len(v) == 1 {
+			return Cell(v[0])
+		}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// orderedKeys returns map keys with Gremlin's id and label first and the
+// rest sorted, so columns are stable across runs.
+func orderedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	rank := func(key string) int {
+		switch key {
+		case "id", "T.id":
+			return 0
+		case "label", "T.label":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := rank(keys[i]), rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
+
+func writeNDJSON(w io.Writer, value any) error {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeYAML(w io.Writer, value any) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlValue(value)); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// yamlValue converts json.Number leaves into native numbers so YAML does not
+// quote them as strings.
+func yamlValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = yamlValue(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = yamlValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func writePlainTable(w io.Writer, columns []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(sanitizeRow(columns), "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(sanitizeRow(row), "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeStyledTable(w io.Writer, columns []string, rows [][]string) error {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4")).Padding(0, 1)
+	cellStyle := lipgloss.NewStyle().Padding(0, 1)
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))).
+		Headers(columns...).
+		StyleFunc(func(row, _ int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			return cellStyle
+		})
+	for _, row := range rows {
+		t.Row(sanitizeRow(row)...)
+	}
+
+	_, err := fmt.Fprintln(w, t.Render())
+	return err
+}
+
+func writeDelimited(w io.Writer, comma rune, columns []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// sanitizeRow keeps aligned tables on one line per row.
+func sanitizeRow(row []string) []string {
+	out := make([]string, len(row))
+	for i, cell := range row {
+		out[i] = strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(cell)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// decodeJSON parses data the way query results are decoded, keeping numbers
+// as json.Number.
+func decodeJSON(t *testing.T, data string) any {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return value
+}
+
+func TestFlattenValueMapResults(t *testing.T) {
+	t.Parallel()
+
+	value := decodeJSON(t, `[
+		{"id":"s1","label":"Study","name":["ABC-001"],"tags":["a","b"]},
+		{"id":"s2","label":"Study","name":["ABC-002"],"phase":["III"]}
+	]`)
+
+	columns, rows := Flatten(value)
+
+	wantColumns := []string{"id", "label", "name", "tags", "phase"}
+	if strings.Join(columns, ",") != strings.Join(wantColumns, ",") {
+		t.Fatalf("expected columns %v, got %v", wantColumns, columns)
+	}
+	if got := strings.Join(rows[0], ","); got != `s1,Study,ABC-001,["a","b"],` {
+		t.Fatalf("unexpected first row: %s", got)
+	}
+	if got := strings.Join(rows[1], ","); got != "s2,Study,ABC-002,,III" {
+		t.Fatalf("unexpected second row: %s", got)
+	}
+}
+
+func TestFlattenScalarResults(t *testing.T) {
+	t.Parallel()
+
+	value := decodeJSON(t, `[42, "x", true]`)
+
+	columns, rows := Flatten(value)
+	if len(columns) != 1 || columns[0] != "value" {
+		t.Fatalf("expected a single value column, got %v", columns)
+	}
+	if len(rows) != 3 || rows[0][0] != "42" || rows[2][0] != "true" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	t.Parallel()
+
+	value := decodeJSON(t, `[{"name":"a, b","count":9007199254740993}]`)
+
+	cases := map[Format]string{
+		CSV:    "count,name\n9007199254740993,\"a, b\"\n",
+		TSV:    "count\tname\n9007199254740993\ta, b\n",
+		NDJSON: "{\"count\":9007199254740993,\"name\":\"a, b\"}\n",
+		YAML:   "- count: 9007199254740993\n  name: a, b\n",
+		Table:  "count             name\n9007199254740993  a, b\n",
+	}
+	for f, want := range cases {
+		var buf bytes.Buffer
+		if err := Write(&buf, value, f, Options{}); err != nil {
+			t.Fatalf("Write(%s): %v", f, err)
+		}
+		if buf.String() != want {
+			t.Fatalf("Write(%s) = %q, want %q", f, buf.String(), want)
+		}
+	}
+}
+
+func TestParseRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Parse("xml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+	if f, err := Parse("TABLE"); err != nil || f != Table {
+		t.Fatalf("expected table, got %q (%v)", f, err)
+	}
+}
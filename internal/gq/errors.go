@@ -0,0 +1,142 @@
+package gq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrModeUnsupported is returned by ExplainQueryContext when the AppSync
+// schema has no mode field on its NeptuneQuery input, so the endpoint cannot
+// explain or profile queries.
+var ErrModeUnsupported = errors.New("explain/profile is not supported by this endpoint")
+
+// maxErrorBodyLength caps how much of an unparseable error body is echoed
+// back in ResponseError messages.
+const maxErrorBodyLength = 512
+
+// ErrorLocation points at a line and column of the GraphQL document.
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is a single entry of a GraphQL response "errors" array.
+// AppSync populates ErrorType for resolver failures (for example
+// "Lambda:Unhandled"), and Message carries the Neptune or Lambda error text.
+type GraphQLError struct {
+	Message    string          `json:"message"`
+	Path       []any           `json:"path,omitempty"`
+	ErrorType  string          `json:"errorType,omitempty"`
+	Locations  []ErrorLocation `json:"locations,omitempty"`
+	Extensions map[string]any  `json:"extensions,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	var b strings.Builder
+	if e.ErrorType != "" {
+		b.WriteString(e.ErrorType)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	if len(e.Path) > 0 {
+		parts := make([]string, len(e.Path))
+		for i, segment := range e.Path {
+			parts[i] = fmt.Sprint(segment)
+		}
+		fmt.Fprintf(&b, " (path: %s)", strings.Join(parts, "."))
+	}
+	return b.String()
+}
+
+// ResponseError reports an AppSync call that returned a non-200 status, a
+// GraphQL "errors" array, or both. Each GraphQLError is reachable through
+// errors.As.
+type ResponseError struct {
+	StatusCode int
+	Errors     []*GraphQLError
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	var b strings.Builder
+	if e.StatusCode != http.StatusOK {
+		fmt.Fprintf(&b, "API returned status code %d", e.StatusCode)
+	}
+
+	if len(e.Errors) == 0 {
+		if body := strings.TrimSpace(e.Body); body != "" {
+			if b.Len() > 0 {
+				b.WriteString(": ")
+			}
+			b.WriteString(truncate(body, maxErrorBodyLength))
+		}
+		return b.String()
+	}
+
+	messages := make([]string, len(e.Errors))
+	for i, gqlErr := range e.Errors {
+		messages[i] = gqlErr.Error()
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(strings.Join(messages, "; "))
+	return b.String()
+}
+
+func (e *ResponseError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, gqlErr := range e.Errors {
+		errs[i] = gqlErr
+	}
+	return errs
+}
+
+// responseError inspects an AppSync response and returns a *ResponseError
+// when it did not succeed, or nil otherwise.
+func responseError(statusCode int, body []byte) error {
+	var envelope struct {
+		Errors []*GraphQLError `json:"errors"`
+	}
+	// Bodies that are not JSON (gateway errors, HTML pages) still produce a
+	// ResponseError for non-200 statuses; the raw text is kept in Body.
+	_ = json.Unmarshal(body, &envelope)
+
+	if statusCode == http.StatusOK && len(envelope.Errors) == 0 {
+		return nil
+	}
+
+	return &ResponseError{
+		StatusCode: statusCode,
+		Errors:     envelope.Errors,
+		Body:       string(body),
+	}
+}
+
+// rejectsInputField reports whether err is AppSync's validation error for an
+// input object carrying field, which its schema does not declare. AppSync
+// words it as "... contains a field not in 'NeptuneQuery': 'mode' ...".
+func rejectsInputField(err error, field string) bool {
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	quoted := "'" + field + "'"
+	for _, gqlErr := range respErr.Errors {
+		message := strings.ToLower(gqlErr.Message)
+		if strings.Contains(message, quoted) && (strings.Contains(message, "field not in") || strings.Contains(message, "unknown field")) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit] + "..."
+}
@@ -25,6 +25,7 @@ type Client struct {
 	awsCfg     aws.Config
 	signer     *v4.Signer
 	region     string
+	retry      RetryPolicy
 }
 
 func NewClient(cfg *config.Config, awsCfg aws.Config) (*Client, error) {
@@ -43,9 +44,15 @@ func NewClient(cfg *config.Config, awsCfg aws.Config) (*Client, error) {
 		awsCfg:     awsCfg,
 		signer:     v4.NewSigner(),
 		region:     region,
+		retry:      retryPolicyFromConfig(cfg),
 	}, nil
 }
 
+// Endpoint returns the AppSync GraphQL URL the client talks to.
+func (c *Client) Endpoint() string {
+	return c.cfg.URL
+}
+
 type GraphQLPayload struct {
 	Query     string `json:"query"`
 	Variables any    `json:"variables"`
@@ -55,18 +62,92 @@ type NeptuneQueryVariables struct {
 	Input struct {
 		Type  string `json:"type"`
 		Query string `json:"query"`
+		// Mode is only sent for explain and profile requests, so resolvers
+		// that predate it keep working for plain queries.
+		Mode Mode `json:"mode,omitempty"`
 	} `json:"input"`
 }
 
-func (c *Client) ExecuteQuery(query string, queryType string) (string, error) {
+// Mode selects what Neptune returns for a query: its result, or the plan
+// from the explain or profile endpoint.
+type Mode string
+
+const (
+	// ModeExecute runs the query and returns its result.
+	ModeExecute Mode = ""
+	// ModeExplain returns the query plan without running the query.
+	ModeExplain Mode = "explain"
+	// ModeProfile runs the query and returns the plan with per-step
+	// timings and counts instead of the result.
+	ModeProfile Mode = "profile"
+)
+
+// ParseMode validates a user-supplied mode name. An empty name selects
+// ModeExecute.
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case ModeExecute, ModeExplain, ModeProfile:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid query mode %q. Must be 'explain' or 'profile'", name)
+}
+
+// ExecuteQuery runs a Gremlin or Cypher query without a deadline. Prefer
+// ExecuteQueryContext so callers can cancel the in-flight request.
+func (c *Client) ExecuteQuery(query string, queryType string, idempotent bool) (string, error) {
+	return c.ExecuteQueryContext(context.Background(), query, queryType, idempotent)
+}
+
+// ExecuteQueryContext runs a Gremlin or Cypher query through the AppSync
+// executeQuery mutation. The request is aborted when ctx is done. The client
+// cannot tell reads from writes, so callers say whether the request is
+// idempotent: idempotent requests are retried according to the client's
+// RetryPolicy, others only when RetryWrites is set.
+func (c *Client) ExecuteQueryContext(ctx context.Context, query string, queryType string, idempotent bool) (string, error) {
+	return c.ExplainQueryContext(ctx, query, queryType, ModeExecute, idempotent)
+}
+
+// ExplainQueryContext is ExecuteQueryContext with a Mode. The mode is passed
+// to the resolver as the "mode" field of the NeptuneQuery input, which
+// routes explain and profile requests to the matching Neptune endpoint.
+func (c *Client) ExplainQueryContext(ctx context.Context, query, queryType string, mode Mode, idempotent bool) (string, error) {
 	variables := NeptuneQueryVariables{}
 	variables.Input.Type = queryType
 	variables.Input.Query = query
+	variables.Input.Mode = mode
 
-	return c.ExecuteGraphQL(`mutation ($input: NeptuneQuery!) { executeQuery(input: $input) }`, variables)
+	body, err := c.executeGraphQL(
+		ctx,
+		`mutation ($input: NeptuneQuery!) { executeQuery(input: $input) }`,
+		variables,
+		idempotent,
+	)
+	if mode != ModeExecute && rejectsInputField(err, "mode") {
+		return body, fmt.Errorf("%w: %s needs a mode field on the NeptuneQuery input of the AppSync schema and a resolver that routes it to Neptune's %s endpoint (see the README): %w", ErrModeUnsupported, mode, mode, err)
+	}
+	return body, err
 }
 
+// ExecuteGraphQL sends an arbitrary GraphQL operation without a deadline.
+// Prefer ExecuteGraphQLContext so callers can cancel the in-flight request.
 func (c *Client) ExecuteGraphQL(query string, variables any) (string, error) {
+	return c.ExecuteGraphQLContext(context.Background(), query, variables)
+}
+
+// ExecuteGraphQLContext signs and sends a GraphQL operation to AppSync and
+// returns the raw response body. The request is aborted when ctx is done.
+// Non-200 responses and responses carrying a GraphQL "errors" array return
+// the body together with a *ResponseError. Query operations are retried
+// according to the client's RetryPolicy; mutations only when RetryWrites is set.
+func (c *Client) ExecuteGraphQLContext(ctx context.Context, query string, variables any) (string, error) {
+	return c.executeGraphQL(ctx, query, variables, isQueryOperation(query))
+}
+
+func (c *Client) executeGraphQL(ctx context.Context, query string, variables any, idempotent bool) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	payload := GraphQLPayload{
 		Query:     query,
 		Variables: variables,
@@ -76,17 +157,32 @@ func (c *Client) ExecuteGraphQL(query string, variables any) (string, error) {
 		return "", fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	ctx := context.Background()
-	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.URL, bytes.NewBuffer(jsonPayload))
+	maxAttempts := c.retry.attempts(idempotent)
+	for attempt := 1; ; attempt++ {
+		body, retryAfter, err := c.send(ctx, jsonPayload)
+		if err == nil || attempt >= maxAttempts || !c.retry.retryable(err) {
+			return body, err
+		}
+
+		if sleepErr := sleepContext(ctx, c.retry.delay(attempt, retryAfter)); sleepErr != nil {
+			return body, fmt.Errorf("request aborted while retrying: %w", sleepErr)
+		}
+	}
+}
+
+// send performs a single signed attempt. Every attempt is signed afresh so
+// retries never reuse an expired SigV4 timestamp.
+func (c *Client) send(ctx context.Context, jsonPayload []byte) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.URL, bytes.NewReader(jsonPayload))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	creds, err := c.awsCfg.Credentials.Retrieve(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to load AWS credentials: %w", err)
+		return "", 0, fmt.Errorf("failed to load AWS credentials: %w", err)
 	}
 
 	payloadHash := sha256.Sum256(jsonPayload)
@@ -99,25 +195,28 @@ func (c *Client) ExecuteGraphQL(query string, variables any) (string, error) {
 		c.region,
 		time.Now(),
 	); err != nil {
-		return "", fmt.Errorf("failed to sign request: %w", err)
+		return "", 0, fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", 0, fmt.Errorf("request aborted: %w", ctxErr)
+		}
+		return "", 0, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status code %d", resp.StatusCode)
-	}
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := responseError(resp.StatusCode, body); err != nil {
+		return string(body), parseRetryAfter(resp.Header.Get("Retry-After")), err
 	}
 
-	return string(body), nil
+	return string(body), 0, nil
 }
 
 func regionFromURL(endpoint string) (string, error) {
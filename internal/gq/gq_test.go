@@ -1,12 +1,17 @@
 package gq
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ankit-lilly/nqcli/internal/config"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -72,3 +77,276 @@ func TestExecuteGraphQLPostsSignedPayload(t *testing.T) {
 		t.Fatalf("unexpected variables: %#v", variables)
 	}
 }
+
+func TestExecuteGraphQLContextHonorsDeadline(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(
+		&config.Config{URL: server.URL},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.ExecuteGraphQLContext(ctx, "query { trials { trialAlias } }", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestExecuteGraphQLReturnsTypedErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name       string
+		status     int
+		body       string
+		wantType   string
+		wantStatus int
+	}{
+		{
+			name:       "200 with errors",
+			status:     http.StatusOK,
+			body:       `{"data":{"executeQuery":null},"errors":[{"path":["executeQuery"],"errorType":"Lambda:Unhandled","message":"ConstraintViolationException","locations":[{"line":1,"column":37}]}]}`,
+			wantType:   "Lambda:Unhandled",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "non-200 with errors",
+			status:     http.StatusUnauthorized,
+			body:       `{"errors":[{"errorType":"UnauthorizedException","message":"Valid authorization header not provided."}]}`,
+			wantType:   "UnauthorizedException",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(
+				&config.Config{URL: server.URL},
+				aws.Config{
+					Region:      "us-east-1",
+					Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+				},
+			)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			raw, err := client.ExecuteQuery("g.V().count()", "gremlin", true)
+			if raw != tc.body {
+				t.Fatalf("expected raw body to be returned, got %q", raw)
+			}
+
+			var respErr *ResponseError
+			if !errors.As(err, &respErr) {
+				t.Fatalf("expected *ResponseError, got %T: %v", err, err)
+			}
+			if respErr.StatusCode != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, respErr.StatusCode)
+			}
+
+			var gqlErr *GraphQLError
+			if !errors.As(err, &gqlErr) {
+				t.Fatalf("expected *GraphQLError, got %v", err)
+			}
+			if gqlErr.ErrorType != tc.wantType {
+				t.Fatalf("expected error type %q, got %q", tc.wantType, gqlErr.ErrorType)
+			}
+			if !strings.Contains(err.Error(), gqlErr.Message) {
+				t.Fatalf("expected error text to include %q, got %q", gqlErr.Message, err.Error())
+			}
+		})
+	}
+}
+
+func TestExecuteQueryRetriesReadsOnThrottling(t *testing.T) {
+	t.Parallel()
+
+	var (
+		attempts   atomic.Int32
+		signatures sync.Map
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+		signatures.Store(r.Header.Get("Authorization"), n)
+		if n == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"executeQuery":"[]"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		&config.Config{URL: server.URL, RetryBaseDelay: time.Millisecond},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := client.ExecuteQuery("g.V().count()", "gremlin", true); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+	signatures.Range(func(key, _ any) bool {
+		if !strings.Contains(key.(string), "AWS4-HMAC-SHA256") {
+			t.Fatalf("expected every attempt to be signed, got %q", key)
+		}
+		return true
+	})
+}
+
+func TestExecuteQueryDoesNotRetryWrites(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		&config.Config{URL: server.URL, RetryBaseDelay: time.Millisecond},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = client.ExecuteQuery("g.addV('Study').property('name','x')", "gremlin", false)
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 ResponseError, got %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("expected a single attempt for a write, got %d", got)
+	}
+}
+
+func TestExplainQuerySendsMode(t *testing.T) {
+	t.Parallel()
+
+	var inputs []map[string]any
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Variables struct {
+				Input map[string]any `json:"input"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		inputs = append(inputs, payload.Variables.Input)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"data":{"executeQuery":"plan"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		&config.Config{URL: server.URL},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := client.ExplainQueryContext(context.Background(), "g.V().count()", "gremlin", ModeProfile, true); err != nil {
+		t.Fatalf("ExplainQueryContext: %v", err)
+	}
+	if _, err := client.ExecuteQuery("g.V().count()", "gremlin", true); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if inputs[0]["mode"] != "profile" {
+		t.Fatalf("expected profile mode, got %#v", inputs[0])
+	}
+	if _, ok := inputs[1]["mode"]; ok {
+		t.Fatalf("expected no mode for a plain query, got %#v", inputs[1])
+	}
+}
+
+func TestExplainQueryReportsMissingModeField(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"errorType":"ValidationError","message":"Validation error of type WrongType: argument 'input' with value 'ObjectValue{}' contains a field not in 'NeptuneQuery': 'mode' @ 'executeQuery'"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(
+		&config.Config{URL: server.URL},
+		aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = client.ExplainQueryContext(context.Background(), "g.V().count()", "gremlin", ModeExplain, true)
+	if !errors.Is(err, ErrModeUnsupported) {
+		t.Fatalf("expected ErrModeUnsupported, got %v", err)
+	}
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("expected the AppSync error to stay reachable, got %v", err)
+	}
+	if _, err := client.ExecuteQuery("g.V().count()", "gremlin", true); errors.Is(err, ErrModeUnsupported) {
+		t.Fatalf("expected plain queries not to report a mode problem, got %v", err)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]Mode{"": ModeExecute, "Explain": ModeExplain, " profile ": ModeProfile} {
+		if got, err := ParseMode(name); err != nil || got != want {
+			t.Fatalf("ParseMode(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMode("analyze"); err == nil {
+		t.Fatalf("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,178 @@
+package gq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/config"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 200 * time.Millisecond
+	defaultRetryMaxDelay    = 5 * time.Second
+)
+
+// RetryPolicy controls how failed AppSync calls are retried. Delays grow
+// exponentially from BaseDelay, are capped at MaxDelay and carry random
+// jitter. A Retry-After header from AppSync takes precedence over the
+// computed delay but is still capped at MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// RetryableStatusCodes lists HTTP statuses that trigger a retry.
+	RetryableStatusCodes []int
+	// RetryWrites also retries operations that may not be idempotent, such as
+	// GraphQL mutations or Gremlin/Cypher queries that write to the graph.
+	RetryWrites bool
+}
+
+// DefaultRetryPolicy retries idempotent reads up to three times on throttling,
+// server errors and dropped connections.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func retryPolicyFromConfig(cfg *config.Config) RetryPolicy {
+	policy := DefaultRetryPolicy()
+	if cfg == nil {
+		return policy
+	}
+	if cfg.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = cfg.RetryMaxAttempts
+	}
+	if cfg.RetryBaseDelay > 0 {
+		policy.BaseDelay = cfg.RetryBaseDelay
+	}
+	if cfg.RetryMaxDelay > 0 {
+		policy.MaxDelay = cfg.RetryMaxDelay
+	}
+	policy.RetryWrites = cfg.RetryWrites
+	return policy
+}
+
+// attempts returns how many times an operation may be tried.
+func (p RetryPolicy) attempts(idempotent bool) int {
+	if !idempotent && !p.RetryWrites {
+		return 1
+	}
+	return max(p.MaxAttempts, 1)
+}
+
+// retryable reports whether err is worth another attempt.
+func (p RetryPolicy) retryable(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		if slices.Contains(p.RetryableStatusCodes, respErr.StatusCode) {
+			return true
+		}
+		for _, gqlErr := range respErr.Errors {
+			if strings.Contains(strings.ToLower(gqlErr.ErrorType), "throttl") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// delay returns how long to wait before the attempt following attempt (1-based).
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	if retryAfter > 0 {
+		return min(retryAfter, maxDelay)
+	}
+
+	backoff := p.BaseDelay
+	for i := 1; i < attempt && backoff < maxDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxDelay)
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half the backoff and randomize the rest.
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var graphQLOperation = regexp.MustCompile(`^\s*(query|mutation|subscription)\b`)
+
+// isQueryOperation reports whether a GraphQL document is a read-only query.
+// Anonymous shorthand documents ("{ ... }") are queries as well.
+func isQueryOperation(document string) bool {
+	match := graphQLOperation.FindStringSubmatch(document)
+	if match == nil {
+		return strings.HasPrefix(strings.TrimSpace(document), "{")
+	}
+	return match[1] == "query"
+}
@@ -0,0 +1,253 @@
+// Package graphson turns GraphSON v2 and v3 results into plain JSON values.
+//
+// Neptune returns Gremlin results with typed wrappers such as
+// {"@type": "g:Int64", "@value": 5}, maps encoded as key/value lists and
+// vertices nested several levels deep. Decode replaces each wrapper with the
+// value a reader expects: numbers as numbers, maps as objects, dates as
+// RFC 3339 strings and vertices, edges and paths as small objects. Values
+// that are already plain JSON pass through unchanged, so decoding twice is
+// harmless.
+package graphson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Unmarshal parses data and decodes the GraphSON in it. Numbers are kept as
+// json.Number so 64-bit ids and counts survive without rounding.
+func Unmarshal(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return Decode(value), nil
+}
+
+// Decode replaces the GraphSON type wrappers in value, a tree as produced by
+// encoding/json, with plain values. Unknown types decode to their @value.
+func Decode(value any) any {
+	switch v := value.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = Decode(item)
+		}
+		return out
+	case map[string]any:
+		if typ, inner, ok := typed(v); ok {
+			return decodeTyped(typ, inner)
+		}
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = Decode(item)
+		}
+		return out
+	}
+	return value
+}
+
+// typed reports whether m is a {"@type", "@value"} wrapper rather than an
+// object that happens to have an @type key.
+func typed(m map[string]any) (string, any, bool) {
+	typ, isString := m["@type"].(string)
+	inner, hasValue := m["@value"]
+	if !isString || !hasValue || len(m) != 2 {
+		return "", nil, false
+	}
+	return typ, inner, true
+}
+
+func decodeTyped(typ string, inner any) any {
+	switch typ {
+	case "g:Int32", "g:Int64", "g:Float", "g:Double", "gx:Int16", "gx:Byte", "gx:BigInteger", "gx:BigDecimal":
+		return inner
+	case "g:Date", "g:Timestamp":
+		return decodeDate(inner)
+	case "g:List", "g:Set":
+		return Decode(inner)
+	case "g:BulkSet":
+		return decodeBulkSet(inner)
+	case "g:Map":
+		return decodeMap(inner)
+	case "g:Vertex":
+		return decodeElement(inner, nil)
+	case "g:Edge":
+		return decodeElement(inner, []string{"outV", "outVLabel", "inV", "inVLabel"})
+	case "g:VertexProperty":
+		return decodeElement(inner, []string{"value"})
+	case "g:Property":
+		return decodeProperty(inner)
+	case "g:Path":
+		return decodePath(inner)
+	case "g:Traverser":
+		if m, ok := inner.(map[string]any); ok {
+			return Decode(m["value"])
+		}
+	}
+	return Decode(inner)
+}
+
+// decodeDate turns milliseconds since the epoch into an RFC 3339 string.
+func decodeDate(inner any) any {
+	var millis int64
+	switch v := inner.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return v
+		}
+		millis = n
+	case float64:
+		if v != math.Trunc(v) {
+			return v
+		}
+		millis = int64(v)
+	default:
+		return Decode(inner)
+	}
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339Nano)
+}
+
+// decodeMap turns the [key1, value1, key2, value2, ...] list of a g:Map into
+// an object. Keys that are not strings, such as vertices in group() results,
+// are written as compact JSON.
+func decodeMap(inner any) any {
+	pairs, ok := inner.([]any)
+	if !ok {
+		return Decode(inner)
+	}
+	out := make(map[string]any, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out[mapKey(Decode(pairs[i]))] = Decode(pairs[i+1])
+	}
+	return out
+}
+
+func mapKey(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case json.Number:
+		return k.String()
+	case nil:
+		return "null"
+	}
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Sprint(key)
+	}
+	return string(encoded)
+}
+
+// decodeBulkSet expands the [value1, bulk1, value2, bulk2, ...] list of a
+// g:BulkSet into a list with each value repeated bulk times.
+func decodeBulkSet(inner any) any {
+	pairs, ok := inner.([]any)
+	if !ok {
+		return Decode(inner)
+	}
+	var out []any
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := Decode(pairs[i])
+		for range bulkCount(Decode(pairs[i+1])) {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// bulkCount returns how often a bulk set holds a value, at least once.
+func bulkCount(bulk any) int64 {
+	var n int64
+	switch b := bulk.(type) {
+	case json.Number:
+		n, _ = b.Int64()
+	case float64:
+		n = int64(b)
+	}
+	return max(n, 1)
+}
+
+// decodeElement turns a vertex, edge or vertex property into an object with
+// its id and label, the named fields present in inner and, when there are
+// any, its properties keyed by name.
+func decodeElement(inner any, fields []string) any {
+	m, ok := inner.(map[string]any)
+	if !ok {
+		return Decode(inner)
+	}
+	out := map[string]any{}
+	for _, field := range append([]string{"id", "label"}, fields...) {
+		if value, ok := m[field]; ok {
+			out[field] = Decode(value)
+		}
+	}
+	if props, ok := m["properties"].(map[string]any); ok && len(props) > 0 {
+		out["properties"] = decodeProperties(props)
+	}
+	return out
+}
+
+// decodeProperties reduces the property objects of an element to their
+// values. Vertex properties come as lists, since a key may have several
+// values; a single value is unwrapped.
+func decodeProperties(props map[string]any) map[string]any {
+	out := make(map[string]any, len(props))
+	for key, value := range props {
+		decoded := Decode(value)
+		list, isList := decoded.([]any)
+		if !isList {
+			out[key] = propertyValue(decoded)
+			continue
+		}
+		values := make([]any, len(list))
+		for i, item := range list {
+			values[i] = propertyValue(item)
+		}
+		if len(values) == 1 {
+			out[key] = values[0]
+		} else {
+			out[key] = values
+		}
+	}
+	return out
+}
+
+// propertyValue returns the value of a decoded g:VertexProperty or
+// g:Property, or item itself when it is already a plain value.
+func propertyValue(item any) any {
+	if m, ok := item.(map[string]any); ok {
+		if value, ok := m["value"]; ok {
+			return value
+		}
+	}
+	return item
+}
+
+func decodeProperty(inner any) any {
+	m, ok := inner.(map[string]any)
+	if !ok {
+		return Decode(inner)
+	}
+	return map[string]any{
+		"key":   Decode(m["key"]),
+		"value": Decode(m["value"]),
+	}
+}
+
+func decodePath(inner any) any {
+	m, ok := inner.(map[string]any)
+	if !ok {
+		return Decode(inner)
+	}
+	return map[string]any{
+		"labels":  Decode(m["labels"]),
+		"objects": Decode(m["objects"]),
+	}
+}
@@ -0,0 +1,93 @@
+package graphson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeString(t *testing.T, input string) string {
+	t.Helper()
+	decoded, err := Unmarshal([]byte(input))
+	if err != nil {
+		t.Fatalf("Unmarshal(%s): %v", input, err)
+	}
+	out, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return string(out)
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "typed numbers keep their digits",
+			input: `{"@type":"g:List","@value":[{"@type":"g:Int64","@value":9007199254740993},{"@type":"g:Double","@value":1.5}]}`,
+			want:  `[9007199254740993,1.5]`,
+		},
+		{
+			name:  "map pairs become an object",
+			input: `{"@type":"g:Map","@value":[{"@type":"g:T","@value":"label"},"Study","name",{"@type":"g:List","@value":["ABC-123"]},{"@type":"g:Int32","@value":7},true]}`,
+			want:  `{"7":true,"label":"Study","name":["ABC-123"]}`,
+		},
+		{
+			name:  "date becomes RFC 3339",
+			input: `{"@type":"g:Date","@value":1700000000123}`,
+			want:  `"2023-11-14T22:13:20.123Z"`,
+		},
+		{
+			name: "vertex with properties",
+			input: `{"@type":"g:Vertex","@value":{"id":"v1","label":"Study","properties":{` +
+				`"name":[{"@type":"g:VertexProperty","@value":{"id":"p1","label":"name","value":"ABC-123"}}],` +
+				`"tag":[{"@type":"g:VertexProperty","@value":{"id":"p2","label":"tag","value":"a"}},{"@type":"g:VertexProperty","@value":{"id":"p3","label":"tag","value":"b"}}]}}}`,
+			want: `{"id":"v1","label":"Study","properties":{"name":"ABC-123","tag":["a","b"]}}`,
+		},
+		{
+			name: "edge with properties",
+			input: `{"@type":"g:Edge","@value":{"id":"e1","label":"has_version","inVLabel":"Version","outVLabel":"Study","inV":"v2","outV":"v1",` +
+				`"properties":{"since":{"@type":"g:Property","@value":{"key":"since","value":{"@type":"g:Int32","@value":2020}}}}}}`,
+			want: `{"id":"e1","inV":"v2","inVLabel":"Version","label":"has_version","outV":"v1","outVLabel":"Study","properties":{"since":2020}}`,
+		},
+		{
+			name:  "bulk set expands",
+			input: `{"@type":"g:BulkSet","@value":["a",{"@type":"g:Int64","@value":2},"b",{"@type":"g:Int64","@value":1}]}`,
+			want:  `["a","a","b"]`,
+		},
+		{
+			name: "path",
+			input: `{"@type":"g:Path","@value":{"labels":{"@type":"g:List","@value":[{"@type":"g:Set","@value":["s"]},{"@type":"g:Set","@value":[]}]},` +
+				`"objects":{"@type":"g:List","@value":[{"@type":"g:Vertex","@value":{"id":"v1","label":"Study"}},"x"]}}}`,
+			want: `{"labels":[["s"],[]],"objects":[{"id":"v1","label":"Study"},"x"]}`,
+		},
+		{
+			name:  "plain JSON passes through",
+			input: `{"data":[{"name":["ABC-123"],"@type":"not a wrapper"}]}`,
+			want:  `{"data":[{"@type":"not a wrapper","name":["ABC-123"]}]}`,
+		},
+		{
+			name:  "unknown types unwrap",
+			input: `{"@type":"g:UUID","@value":"41d2e28a-20a4-4ab0-b379-d810dede3786"}`,
+			want:  `"41d2e28a-20a4-4ab0-b379-d810dede3786"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeString(t, tt.input); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeIsIdempotent(t *testing.T) {
+	input := `{"@type":"g:List","@value":[{"@type":"g:Vertex","@value":{"id":{"@type":"g:Int64","@value":1},"label":"Study"}}]}`
+	once := decodeString(t, input)
+	if twice := decodeString(t, once); twice != once {
+		t.Fatalf("decoding twice changed the result: %s then %s", once, twice)
+	}
+}
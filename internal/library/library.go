@@ -0,0 +1,283 @@
+// Package library keeps saved queries: named, parameterized Gremlin or Cypher
+// queries stored one per YAML file, so the traversals a team reuses live in
+// one place instead of chat history. A library reads from a personal
+// directory and, optionally, a shared team directory; personal queries win
+// when both hold the same name. Only the personal directory is written to.
+//
+// A saved query file looks like:
+//
+//	name: study-versions
+//	description: Versions of a study, newest first
+//	language: gremlin
+//	query: g.V().has('Study', 'name', $study).out('has_version').limit($limit)
+//	params:
+//	  limit: 10
+//	tags: [study, versions]
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ankit-lilly/nqcli/internal/params"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotFound is returned for a name no library directory holds.
+var ErrNotFound = errors.New("saved query not found")
+
+// ErrExists is returned when saving over an existing query without
+// overwrite.
+var ErrExists = errors.New("saved query already exists")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Query is one saved query.
+type Query struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Language    string `yaml:"language" json:"language"`
+	Query       string `yaml:"query" json:"query"`
+	// Params holds default values for the query's $name placeholders.
+	Params map[string]any `yaml:"params,omitempty" json:"params,omitempty"`
+	Tags   []string       `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Shared reports whether the query comes from the shared directory.
+	Shared bool `yaml:"-" json:"shared,omitempty"`
+	// Path is the file the query was read from or saved to.
+	Path string `yaml:"-" json:"-"`
+}
+
+// Validate checks that q can be saved and run.
+func (q *Query) Validate() error {
+	if !namePattern.MatchString(q.Name) {
+		return fmt.Errorf("invalid saved query name %q: use letters, digits, '.', '_' and '-'", q.Name)
+	}
+	if q.Language != "gremlin" && q.Language != "cypher" {
+		return fmt.Errorf("invalid language %q for saved query %q. Must be 'gremlin' or 'cypher'", q.Language, q.Name)
+	}
+	if strings.TrimSpace(q.Query) == "" {
+		return fmt.Errorf("saved query %q has no query text", q.Name)
+	}
+	return nil
+}
+
+// HasTag reports whether q is tagged tag, ignoring case.
+func (q *Query) HasTag(tag string) bool {
+	return slices.ContainsFunc(q.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// Render returns the query text with its placeholders bound to bindings,
+// falling back to the saved defaults. A placeholder with neither is an
+// error, even when there are no bindings at all.
+func (q *Query) Render(bindings map[string]any) (string, error) {
+	merged := params.Merge(q.Params, bindings)
+	var missing []string
+	for _, name := range params.Names(q.Query) {
+		if _, ok := merged[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing value for parameter(s) of saved query %q: %s", q.Name, strings.Join(missing, ", "))
+	}
+	return params.Render(q.Query, q.Language, merged)
+}
+
+// Library is a personal saved query directory plus an optional shared one.
+type Library struct {
+	Dir       string
+	SharedDir string
+}
+
+// New returns a library reading dir and sharedDir (which may be empty).
+// Neither needs to exist yet.
+func New(dir, sharedDir string) *Library {
+	return &Library{Dir: dir, SharedDir: sharedDir}
+}
+
+// List returns every saved query sorted by name, personal queries hiding
+// shared ones of the same name. Files that cannot be read or parsed are
+// skipped and returned as problems, so one bad file does not hide the rest
+// of the library; err is only set when a directory cannot be listed.
+func (l *Library) List() (queries []Query, problems []error, err error) {
+	byName := make(map[string]Query)
+	for _, source := range []struct {
+		dir    string
+		shared bool
+	}{{l.SharedDir, true}, {l.Dir, false}} {
+		found, bad, err := readDir(source.dir, source.shared)
+		if err != nil {
+			return nil, nil, err
+		}
+		problems = append(problems, bad...)
+		for _, q := range found {
+			byName[q.Name] = q
+		}
+	}
+
+	queries = make([]Query, 0, len(byName))
+	for _, q := range byName {
+		queries = append(queries, q)
+	}
+	slices.SortFunc(queries, func(a, b Query) int { return strings.Compare(a.Name, b.Name) })
+	return queries, problems, nil
+}
+
+// Get returns the saved query called name. Names that could not have been
+// saved, such as paths, are not found.
+func (l *Library) Get(name string) (*Query, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: invalid name %q", ErrNotFound, name)
+	}
+	for _, source := range []struct {
+		dir    string
+		shared bool
+	}{{l.Dir, false}, {l.SharedDir, true}} {
+		if source.dir == "" {
+			continue
+		}
+		q, err := readFile(filepath.Join(source.dir, name+".yaml"), source.shared)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		return q, err
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+}
+
+// Save writes q to the personal directory as NAME.yaml. An existing query
+// of the same name is replaced only when overwrite is set.
+func (l *Library) Save(q Query, overwrite bool) (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
+	if l.Dir == "" {
+		return "", fmt.Errorf("no saved query directory is configured")
+	}
+
+	path := filepath.Join(l.Dir, q.Name+".yaml")
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return "", fmt.Errorf("%w: %q (%s)", ErrExists, q.Name, path)
+	}
+
+	for name, value := range q.Params {
+		q.Params[name] = plainValue(value)
+	}
+	data, err := Marshal(q)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create saved query directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to save query %q: %w", q.Name, err)
+	}
+	return path, nil
+}
+
+// plainValue turns the json.Number values --param produces into integers or
+// floats so they are saved as YAML numbers rather than strings.
+func plainValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		for i := range v {
+			v[i] = plainValue(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = plainValue(v[k])
+		}
+	}
+	return value
+}
+
+// Marshal returns q as it is stored on disk.
+func Marshal(q Query) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(q); err != nil {
+		return nil, fmt.Errorf("failed to encode saved query %q: %w", q.Name, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// readDir reads every saved query in dir, collecting the files that fail
+// as problems. A missing directory holds no queries.
+func readDir(dir string, shared bool) (queries []Query, problems []error, err error) {
+	if dir == "" {
+		return nil, nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read saved query directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		q, err := readFile(filepath.Join(dir, entry.Name()), shared)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		queries = append(queries, *q)
+	}
+	return queries, problems, nil
+}
+
+// readFile reads one saved query. The file name is the query's name; a name
+// field that disagrees with it is an error rather than a silent rename.
+func readFile(path string, shared bool) (*Query, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var q Query
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&q); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse saved query %q: %w", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+	if q.Name == "" {
+		q.Name = name
+	}
+	if q.Name != name {
+		return nil, fmt.Errorf("saved query %q is named %q; the name must match the file name", path, q.Name)
+	}
+	if q.Language == "" {
+		q.Language = "gremlin"
+	}
+	if err := q.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	q.Shared, q.Path = shared, path
+	return &q, nil
+}
@@ -0,0 +1,146 @@
+package library
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeQuery(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestSaveAndGet(t *testing.T) {
+	lib := New(filepath.Join(t.TempDir(), "queries"), "")
+	q := Query{
+		Name:        "study-versions",
+		Description: "Versions of a study",
+		Language:    "gremlin",
+		Query:       "g.V().has('Study', 'name', $study).out('has_version').limit($limit)",
+		Params:      map[string]any{"limit": 10},
+		Tags:        []string{"study"},
+	}
+
+	if _, err := lib.Save(q, false); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := lib.Save(q, false); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists saving twice, got %v", err)
+	}
+	if _, err := lib.Save(q, true); err != nil {
+		t.Fatalf("Save with overwrite: %v", err)
+	}
+
+	got, err := lib.Get("study-versions")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Description != q.Description || !got.HasTag("STUDY") || got.Shared {
+		t.Fatalf("unexpected saved query: %+v", got)
+	}
+
+	rendered, err := got.Render(map[string]any{"study": "LY01"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := "g.V().has('Study', 'name', 'LY01').out('has_version').limit(10)"; rendered != want {
+		t.Fatalf("Render = %q, want %q", rendered, want)
+	}
+
+	if _, err := lib.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSaveRejectsInvalidQueries(t *testing.T) {
+	lib := New(t.TempDir(), "")
+	for _, q := range []Query{
+		{Name: "../escape", Language: "gremlin", Query: "g.V()"},
+		{Name: "ok", Language: "sparql", Query: "SELECT *"},
+		{Name: "ok", Language: "cypher", Query: "  "},
+	} {
+		if _, err := lib.Save(q, false); err == nil {
+			t.Fatalf("expected Save(%+v) to fail", q)
+		}
+	}
+}
+
+func TestListPrefersPersonalQueries(t *testing.T) {
+	dir, shared := t.TempDir(), t.TempDir()
+	writeQuery(t, shared, "counts", "query: g.V().count()\ndescription: team version\n")
+	writeQuery(t, shared, "studies", "language: cypher\nquery: MATCH (s:Study) RETURN s\n")
+	writeQuery(t, dir, "counts", "query: g.V().count()\ndescription: my version\n")
+
+	queries, problems, err := New(dir, shared).List()
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("List: %v %v", err, problems)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 queries, got %+v", queries)
+	}
+	if queries[0].Name != "counts" || queries[0].Description != "my version" || queries[0].Shared {
+		t.Fatalf("expected the personal counts query, got %+v", queries[0])
+	}
+	if queries[1].Name != "studies" || queries[1].Language != "cypher" || !queries[1].Shared {
+		t.Fatalf("expected the shared studies query, got %+v", queries[1])
+	}
+}
+
+func TestListSkipsBadFiles(t *testing.T) {
+	dir, shared := t.TempDir(), t.TempDir()
+	writeQuery(t, dir, "counts", "name: other\nquery: g.V().count()\n")
+	writeQuery(t, shared, "broken", "query: [unterminated\n")
+	writeQuery(t, shared, "studies", "query: g.V().hasLabel('Study')\n")
+
+	queries, problems, err := New(dir, shared).List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(queries) != 1 || queries[0].Name != "studies" {
+		t.Fatalf("expected only the good query, got %+v", queries)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("expected a problem for each bad file, got %v", problems)
+	}
+	for _, name := range []string{"broken.yaml", "counts.yaml"} {
+		if !slices.ContainsFunc(problems, func(err error) bool { return strings.Contains(err.Error(), name) }) {
+			t.Fatalf("expected a problem naming %s, got %v", name, problems)
+		}
+	}
+
+	if _, _, err := New(filepath.Join(dir, "counts.yaml"), "").List(); err == nil {
+		t.Fatalf("expected an error when the directory cannot be read")
+	}
+}
+
+func TestGetRejectsPaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "queries")
+	writeQuery(t, root, "outside", "query: g.V().count()\n")
+
+	for _, name := range []string{"../outside", "sub/../../outside", filepath.Join(root, "outside")} {
+		if _, err := New(dir, "").Get(name); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get(%q): expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestRenderRequiresEveryPlaceholder(t *testing.T) {
+	q := Query{Name: "study", Language: "gremlin", Query: "g.V().has('Study', 'name', $study)"}
+
+	if _, err := q.Render(nil); err == nil || !strings.Contains(err.Error(), "study") {
+		t.Fatalf("expected a missing value error for $study, got %v", err)
+	}
+	if rendered, err := q.Render(map[string]any{"study": "LY01"}); err != nil || rendered != "g.V().has('Study', 'name', 'LY01')" {
+		t.Fatalf("Render = %q, %v", rendered, err)
+	}
+}
@@ -0,0 +1,310 @@
+// Package lint checks Gremlin traversals against a graph schema before they
+// run, so that a misspelled label, edge or property key is reported instead
+// of silently matching nothing.
+//
+// The check is lexical, like package safety: the query is tokenized, step
+// calls are matched by name, and only string literal arguments are checked.
+// Anything it cannot follow is accepted, so a warning is a strong hint rather
+// than proof that the query is wrong.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ankit-lilly/nqcli/internal/schema"
+)
+
+// Kinds of names a warning can be about.
+const (
+	KindVertexLabel = "vertex label"
+	KindEdgeLabel   = "edge label"
+	KindLabel       = "label"
+	KindProperty    = "property"
+)
+
+// Warning is one name in a query that the schema does not know.
+type Warning struct {
+	// Offset is the byte offset of the string literal in the query.
+	Offset int    `json:"offset"`
+	Step   string `json:"step"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// Suggestion is the closest known name, if any is close enough.
+	Suggestion string `json:"suggestion,omitempty"`
+	Message    string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Message
+}
+
+// Linter checks queries against one schema. A nil Linter accepts everything.
+type Linter struct {
+	vertexLabels []string
+	edgeLabels   []string
+	properties   []string
+	// labelProperties holds the property keys of each label that has any.
+	labelProperties map[string][]string
+}
+
+// New returns a linter for g. Property keys are only checked when g lists
+// at least one.
+func New(g *schema.Graph) *Linter {
+	l := &Linter{
+		vertexLabels:    slices.Clone(g.VertexLabels),
+		edgeLabels:      slices.Clone(g.EdgeLabels),
+		labelProperties: make(map[string][]string),
+	}
+	seen := make(map[string]bool)
+	for _, labels := range []map[string]schema.Label{g.Vertices, g.Edges} {
+		for label, info := range labels {
+			for _, prop := range info.Properties {
+				l.labelProperties[label] = append(l.labelProperties[label], prop.Name)
+				if !seen[prop.Name] {
+					seen[prop.Name] = true
+					l.properties = append(l.properties, prop.Name)
+				}
+			}
+		}
+	}
+	slices.Sort(l.properties)
+	return l
+}
+
+// element is what the traversal is positioned on, as far as the linter can
+// tell.
+type element int
+
+const (
+	unknownElement element = iota
+	vertexElement
+	edgeElement
+)
+
+var (
+	vertexSteps = stepSet("V", "out", "in", "both", "outV", "inV", "bothV", "otherV", "addV", "mergeV")
+	edgeSteps   = stepSet("E", "outE", "inE", "bothE", "addE", "mergeE")
+	// filterSteps keep the traversal on the same kind of element.
+	filterSteps = stepSet(
+		"has", "hasLabel", "hasId", "hasKey", "hasValue", "hasNot", "where", "filter", "not", "and", "or",
+		"is", "limit", "range", "skip", "tail", "sample", "coin", "dedup", "order", "by", "as",
+		"simplePath", "cyclicPath", "timeLimit", "identity", "barrier", "aggregate", "store",
+		"sideEffect", "property", "emit", "times", "until",
+	)
+	edgeLabelSteps = stepSet("out", "in", "both", "outE", "inE", "bothE")
+	propertySteps  = stepSet("hasNot", "values", "properties", "valueMap", "elementMap", "propertyMap", "by")
+)
+
+func stepSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// Check returns warnings for the labels, edge labels and property keys in
+// query that the schema does not contain. Only Gremlin is checked; other
+// query types return nil.
+func (l *Linter) Check(query, queryType string) []Warning {
+	if l == nil || !strings.EqualFold(strings.TrimSpace(queryType), "gremlin") {
+		return nil
+	}
+
+	tokens := tokenize(query)
+	var (
+		warnings []Warning
+		reported = make(map[string]bool)
+		current  element
+		// frames restores the element when a parenthesis closes: steps
+		// nested in arguments start from the enclosing element, and the
+		// step itself moves the traversal once its arguments end.
+		frames []element
+	)
+	report := func(w Warning) {
+		if key := w.Kind + "\x00" + w.Name + "\x00" + w.Step; !reported[key] {
+			reported[key] = true
+			warnings = append(warnings, w)
+		}
+	}
+
+	for i, tok := range tokens {
+		switch {
+		case tok.kind == tokenPunct && tok.text == ")":
+			if n := len(frames); n > 0 {
+				current, frames = frames[n-1], frames[:n-1]
+			}
+		case tok.kind == tokenPunct && tok.text == "(":
+			after := current
+			if i > 0 && tokens[i-1].kind == tokenIdent {
+				after = stepResult(tokens[i-1].text, current)
+			}
+			frames = append(frames, after)
+		case tok.kind == tokenIdent && i+1 < len(tokens) && tokens[i+1].kind == tokenPunct && tokens[i+1].text == "(":
+			for _, w := range l.checkStep(tok.text, callArgs(tokens, i+1), current) {
+				report(w)
+			}
+		}
+	}
+	return warnings
+}
+
+func stepResult(step string, current element) element {
+	switch {
+	case vertexSteps[step]:
+		return vertexElement
+	case edgeSteps[step]:
+		return edgeElement
+	case filterSteps[step]:
+		return current
+	}
+	return unknownElement
+}
+
+// checkStep checks the string literal arguments of one step call.
+func (l *Linter) checkStep(step string, args []*token, current element) []Warning {
+	var warnings []Warning
+	add := func(w *Warning) {
+		if w != nil {
+			warnings = append(warnings, *w)
+		}
+	}
+
+	switch {
+	case step == "hasLabel":
+		for _, arg := range args {
+			if arg != nil {
+				add(l.checkLabel(step, arg, current))
+			}
+		}
+	case step == "has":
+		// has(label, key, value) names a label; has(key), has(key, value)
+		// and has(key, predicate) only a key.
+		if len(args) >= 3 && args[0] != nil && args[1] != nil {
+			if w := l.checkLabel(step, args[0], current); w != nil {
+				add(w)
+				break
+			}
+			add(l.checkProperty(step, args[1], args[0].text))
+		} else if len(args) > 0 && args[0] != nil {
+			add(l.checkProperty(step, args[0], ""))
+		}
+	case edgeLabelSteps[step]:
+		for _, arg := range args {
+			if arg != nil {
+				add(l.checkName(step, arg, KindEdgeLabel, l.edgeLabels))
+			}
+		}
+	case propertySteps[step]:
+		for _, arg := range args {
+			if arg != nil {
+				add(l.checkProperty(step, arg, ""))
+			}
+		}
+	}
+	return warnings
+}
+
+func (l *Linter) checkLabel(step string, arg *token, current element) *Warning {
+	if slices.Contains(l.vertexLabels, arg.text) || slices.Contains(l.edgeLabels, arg.text) {
+		return nil
+	}
+	switch current {
+	case vertexElement:
+		return l.checkName(step, arg, KindVertexLabel, l.vertexLabels)
+	case edgeElement:
+		return l.checkName(step, arg, KindEdgeLabel, l.edgeLabels)
+	}
+	return l.checkName(step, arg, KindLabel, append(slices.Clone(l.vertexLabels), l.edgeLabels...))
+}
+
+// checkProperty checks a property key, against the properties of label when
+// the step names one.
+func (l *Linter) checkProperty(step string, arg *token, label string) *Warning {
+	if len(l.properties) == 0 {
+		return nil
+	}
+	if w := l.checkName(step, arg, KindProperty, l.properties); w != nil {
+		return w
+	}
+	props := l.labelProperties[label]
+	if label == "" || len(props) == 0 || slices.Contains(props, arg.text) {
+		return nil
+	}
+	w := newWarning(step, arg, KindProperty, suggest(arg.text, props))
+	w.Message = fmt.Sprintf("%s(%s): property %q is not defined on %s", step, quote(arg.text), arg.text, label)
+	if w.Suggestion != "" {
+		w.Message += fmt.Sprintf("; did you mean %q?", w.Suggestion)
+	}
+	return w
+}
+
+func (l *Linter) checkName(step string, arg *token, kind string, known []string) *Warning {
+	if slices.Contains(known, arg.text) {
+		return nil
+	}
+	w := newWarning(step, arg, kind, suggest(arg.text, known))
+	w.Message = fmt.Sprintf("%s(%s): unknown %s %q", step, quote(arg.text), kind, arg.text)
+	if w.Suggestion != "" {
+		w.Message += fmt.Sprintf("; did you mean %q?", w.Suggestion)
+	}
+	return w
+}
+
+func newWarning(step string, arg *token, kind, suggestion string) *Warning {
+	return &Warning{
+		Offset:     arg.offset,
+		Step:       step,
+		Kind:       kind,
+		Name:       arg.text,
+		Suggestion: suggestion,
+	}
+}
+
+func quote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `\'`) + "'"
+}
+
+// suggest returns the known name closest to name, or "" when none is within
+// a third of its length in edits. Ties go to the alphabetically first name.
+func suggest(name string, known []string) string {
+	limit := max(1, min(3, len([]rune(name))/3))
+	best, bestDistance := "", limit+1
+	for _, candidate := range known {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance || distance == bestDistance && candidate < best {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance between a and b, in
+// runes: insertions, deletions, substitutions and swaps of adjacent runes
+// each count as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,87 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ankit-lilly/nqcli/internal/schema"
+)
+
+func testLinter() *Linter {
+	return New(&schema.Graph{
+		VertexLabels: []string{"Study", "StudyVersion"},
+		EdgeLabels:   []string{"has_version"},
+		Vertices: map[string]schema.Label{
+			"Study":        {Properties: []schema.Property{{Name: "name"}, {Name: "phase"}}},
+			"StudyVersion": {Properties: []schema.Property{{Name: "versionIdentifier"}}},
+		},
+	})
+}
+
+func messages(warnings []Warning) string {
+	var lines []string
+	for _, w := range warnings {
+		lines = append(lines, w.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCheckAcceptsKnownNames(t *testing.T) {
+	for _, query := range []string{
+		"g.V().hasLabel('Study').has('name', 'ABC').out('has_version').values('versionIdentifier')",
+		"g.V().has('Study', 'phase', within('I', 'II')).order().by('name').valueMap('name', 'phase')",
+		"g.E().hasLabel('has_version').count()",
+		"g.V().has(T.label, 'Anything').where(__.out('has_version')).project('n').by('name')",
+		"g.V().has('name', 'Stdy').values('name') // hasLabel('Stdy')",
+		"g.addV('NewLabel').property('newKey', 1)",
+	} {
+		if warnings := testLinter().Check(query, "gremlin"); len(warnings) > 0 {
+			t.Fatalf("unexpected warnings for %s:\n%s", query, messages(warnings))
+		}
+	}
+}
+
+func TestCheckSuggestsCloseNames(t *testing.T) {
+	query := `g.V().hasLabel("Stdy").out('has_versions').has('StudyVersion', 'versionIdentfier', 'v1').values('nmae', 'zzz')`
+	warnings := testLinter().Check(query, "gremlin")
+
+	want := []string{
+		`hasLabel('Stdy'): unknown vertex label "Stdy"; did you mean "Study"?`,
+		`out('has_versions'): unknown edge label "has_versions"; did you mean "has_version"?`,
+		`has('versionIdentfier'): unknown property "versionIdentfier"; did you mean "versionIdentifier"?`,
+		`values('nmae'): unknown property "nmae"; did you mean "name"?`,
+		`values('zzz'): unknown property "zzz"`,
+	}
+	if got := messages(warnings); got != strings.Join(want, "\n") {
+		t.Fatalf("unexpected warnings:\n%s", got)
+	}
+	if warnings[1].Offset != strings.Index(query, "'has_versions'") || warnings[1].Suggestion != "has_version" {
+		t.Fatalf("unexpected warning details %+v", warnings[1])
+	}
+}
+
+func TestCheckUsesLabelProperties(t *testing.T) {
+	warnings := testLinter().Check("g.V().has('StudyVersion', 'name', 'x')", "gremlin")
+	if got := messages(warnings); got != `has('name'): property "name" is not defined on StudyVersion` {
+		t.Fatalf("unexpected warnings:\n%s", got)
+	}
+}
+
+func TestCheckTracksEdgeElements(t *testing.T) {
+	warnings := testLinter().Check("g.V().outE().hasLabel('has_verison').inV().hasLabel('Studyy')", "gremlin")
+	want := `hasLabel('has_verison'): unknown edge label "has_verison"; did you mean "has_version"?` + "\n" +
+		`hasLabel('Studyy'): unknown vertex label "Studyy"; did you mean "Study"?`
+	if got := messages(warnings); got != want {
+		t.Fatalf("unexpected warnings:\n%s", got)
+	}
+}
+
+func TestCheckSkipsCypherAndNilLinter(t *testing.T) {
+	if warnings := testLinter().Check("MATCH (s:Stdy) RETURN s", "cypher"); warnings != nil {
+		t.Fatalf("expected Cypher to be skipped, got %v", warnings)
+	}
+	var l *Linter
+	if warnings := l.Check("g.V().hasLabel('Stdy')", "gremlin"); warnings != nil {
+		t.Fatalf("expected nil linter to accept everything, got %v", warnings)
+	}
+}
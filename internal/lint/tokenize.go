@@ -0,0 +1,127 @@
+package lint
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenPunct
+	tokenOther
+)
+
+// token is a lexical unit of a Gremlin query. For strings, text holds the
+// unescaped value and offset points at the opening quote.
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// tokenize splits query into identifiers, string literals, punctuation and
+// other runs such as numbers. Comments and whitespace are dropped; an
+// unterminated string runs to the end of the query.
+func tokenize(query string) []token {
+	var tokens []token
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += 2 + end + 2
+		case ch == '\'' || ch == '"':
+			var text strings.Builder
+			j := i + 1
+			for j < len(query) && query[j] != ch {
+				if query[j] == '\\' && j+1 < len(query) {
+					j++
+				}
+				text.WriteByte(query[j])
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), offset: i})
+			i = j + 1
+		case isIdentStart(ch):
+			j := i + 1
+			for j < len(query) && (isIdentStart(query[j]) || '0' <= query[j] && query[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: query[i:j], offset: i})
+			i = j
+		case strings.IndexByte("().,[]{}", ch) >= 0:
+			tokens = append(tokens, token{kind: tokenPunct, text: query[i : i+1], offset: i})
+			i++
+		default:
+			j := i + 1
+			for j < len(query) && !isBoundary(query[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenOther, text: query[i:j], offset: i})
+			i = j
+		}
+	}
+	return tokens
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+func isBoundary(ch byte) bool {
+	return strings.IndexByte(" \t\n\r'\"().,[]{}", ch) >= 0 || isIdentStart(ch)
+}
+
+// callArgs returns the top-level arguments of the call whose opening
+// parenthesis is tokens[open]. An argument that is a single string literal is
+// returned as that token; any other argument is nil.
+func callArgs(tokens []token, open int) []*token {
+	var (
+		args  []*token
+		start = open + 1
+		depth = 0
+	)
+	for i := open; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind != tokenPunct {
+			continue
+		}
+		switch tok.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case ",":
+			if depth == 1 {
+				args = append(args, literalArg(tokens[start:i]))
+				start = i + 1
+			}
+			continue
+		}
+		if depth == 0 {
+			if i > start || len(args) > 0 {
+				args = append(args, literalArg(tokens[start:i]))
+			}
+			return args
+		}
+	}
+	return args
+}
+
+func literalArg(arg []token) *token {
+	if len(arg) != 1 || arg[0].kind != tokenString {
+		return nil
+	}
+	return &arg[0]
+}
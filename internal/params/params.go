@@ -0,0 +1,348 @@
+// Package params binds named parameters into Gremlin and Cypher queries.
+//
+// AppSync forwards a single query string to Neptune, so bindings cannot be
+// sent alongside the query. Instead, $name placeholders outside of string
+// literals are replaced with literals rendered for the target language, which
+// keeps quoting and escaping in one well-tested place.
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseAssignments parses name=value pairs as given to --param. Values that
+// are valid JSON (numbers, booleans, null, lists, maps, quoted strings) are
+// decoded; anything else is taken as a plain string.
+func ParseAssignments(pairs []string) (map[string]any, error) {
+	bindings := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid parameter %q: expected name=value", pair)
+		}
+		if !identifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid parameter name %q", name)
+		}
+		bindings[name] = parseValue(raw)
+	}
+	return bindings, nil
+}
+
+func parseValue(raw string) any {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return raw
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return raw
+	}
+	return value
+}
+
+// LoadFile reads bindings from a JSON or YAML file containing a single
+// top-level mapping.
+func LoadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read params file: %w", err)
+	}
+
+	var bindings map[string]any
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&bindings); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse params file %q: %w", path, err)
+	}
+	for name := range bindings {
+		if !identifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid parameter name %q in %q", name, path)
+		}
+	}
+	return bindings, nil
+}
+
+// Merge combines binding sets; later sets win.
+func Merge(sets ...map[string]any) map[string]any {
+	merged := map[string]any{}
+	for _, set := range sets {
+		for name, value := range set {
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// Render replaces $name placeholders in query with literals for queryType
+// ("gremlin" or "cypher"). Placeholders inside string literals and quoted
+// identifiers are left alone. Without bindings the query is returned
+// unchanged; a placeholder with no binding is an error.
+func Render(query, queryType string, bindings map[string]any) (string, error) {
+	if len(bindings) == 0 {
+		return query, nil
+	}
+
+	literal := GremlinLiteral
+	if strings.EqualFold(queryType, "cypher") {
+		literal = CypherLiteral
+	}
+
+	var (
+		missing   []string
+		renderErr error
+	)
+	rendered := replacePlaceholders(query, func(name, placeholder string) string {
+		value, ok := bindings[name]
+		if !ok {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return placeholder
+		}
+		text, err := literal(value)
+		if err != nil {
+			if renderErr == nil {
+				renderErr = fmt.Errorf("parameter %q: %w", name, err)
+			}
+			return placeholder
+		}
+		return text
+	})
+
+	if renderErr != nil {
+		return "", renderErr
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing value for parameter(s): %s", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+// Names returns the $name placeholders in query in order of first use,
+// skipping any inside string literals and quoted identifiers.
+func Names(query string) []string {
+	var names []string
+	replacePlaceholders(query, func(name, placeholder string) string {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+		return placeholder
+	})
+	return names
+}
+
+// replacePlaceholders returns query with each $name placeholder outside
+// quotes replaced by replace(name, "$name").
+func replacePlaceholders(query string, replace func(name, placeholder string) string) string {
+	var (
+		out   strings.Builder
+		quote rune
+	)
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if quote != 0 {
+			out.WriteRune(r)
+			switch {
+			case r == '\\' && quote != '`' && i+1 < len(runes):
+				i++
+				out.WriteRune(runes[i])
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			out.WriteRune(r)
+		case r == '$' && i+1 < len(runes) && isIdentStart(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && isIdentPart(runes[j]) {
+				j++
+			}
+			out.WriteString(replace(string(runes[i+1:j]), string(runes[i:j])))
+			i = j - 1
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || (r >= '0' && r <= '9')
+}
+
+// QuoteGremlin renders s as a single-quoted Gremlin string literal.
+func QuoteGremlin(s string) string {
+	return "'" + escapeString(s) + "'"
+}
+
+// QuoteCypher renders s as a single-quoted Cypher string literal.
+func QuoteCypher(s string) string {
+	return "'" + escapeString(s) + "'"
+}
+
+// GremlinLiteral renders value as a Gremlin literal. Maps use Groovy-style
+// [key: value] syntax.
+func GremlinLiteral(value any) (string, error) {
+	return renderLiteral(value, QuoteGremlin, func(keys []string, values []string) string {
+		if len(keys) == 0 {
+			return "[:]"
+		}
+		entries := make([]string, len(keys))
+		for i := range keys {
+			entries[i] = QuoteGremlin(keys[i]) + ": " + values[i]
+		}
+		return "[" + strings.Join(entries, ", ") + "]"
+	})
+}
+
+// CypherLiteral renders value as an openCypher literal. Map keys that are
+// not plain identifiers are backtick-quoted.
+func CypherLiteral(value any) (string, error) {
+	return renderLiteral(value, QuoteCypher, func(keys []string, values []string) string {
+		entries := make([]string, len(keys))
+		for i := range keys {
+			key := keys[i]
+			if !identifierPattern.MatchString(key) {
+				key = "`" + strings.ReplaceAll(key, "`", "``") + "`"
+			}
+			entries[i] = key + ": " + values[i]
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	})
+}
+
+func renderLiteral(value any, quote func(string) string, renderMap func(keys, values []string) string) (string, error) {
+	render := func(v any) (string, error) { return renderLiteral(v, quote, renderMap) }
+
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		if _, err := v.Float64(); err != nil {
+			return "", fmt.Errorf("invalid number %q", v)
+		}
+		return v.String(), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("cannot render %v as a literal", v)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			rendered, err := render(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = rendered
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			rendered, err := render(v[key])
+			if err != nil {
+				return "", err
+			}
+			values[i] = rendered
+		}
+		return renderMap(keys, values), nil
+	}
+
+	// Fall back to reflection for typed slices and maps (for example
+	// []string from Go callers or map[any]any from older YAML decoders).
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return render(items)
+	case reflect.Map:
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+		}
+		return render(m)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return render(rv.Float())
+	}
+	return "", fmt.Errorf("unsupported parameter type %T", value)
+}
+
+func escapeString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
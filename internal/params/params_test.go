@@ -0,0 +1,104 @@
+package params
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRenderGremlin(t *testing.T) {
+	t.Parallel()
+
+	bindings, err := ParseAssignments([]string{
+		`alias=O'Brien\`,
+		"limit=5",
+		"open=true",
+		`ids=["a","b"]`,
+		`props={"name":"x","n":1.5}`,
+		`quoted="42"`,
+	})
+	if err != nil {
+		t.Fatalf("ParseAssignments: %v", err)
+	}
+
+	got, err := Render(
+		"g.V().has('Study','name',$alias).has('open',$open).has('id',within($ids)).limit($limit).inject($props, $quoted, '$alias')",
+		"gremlin",
+		bindings,
+	)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	want := `g.V().has('Study','name','O\'Brien\\').has('open',true).has('id',within(['a', 'b'])).limit(5).inject(['n': 1.5, 'name': 'x'], '42', '$alias')`
+	if got != want {
+		t.Fatalf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderCypher(t *testing.T) {
+	t.Parallel()
+
+	got, err := Render(
+		"MATCH (s:Study {name: $name}) WHERE s.phase IN $phases SET s += $props RETURN s, \"$name\"",
+		"cypher",
+		map[string]any{
+			"name":   "a\nb",
+			"phases": []string{"I", "II"},
+			"props":  map[string]any{"my key": nil, "ok": false},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	want := "MATCH (s:Study {name: 'a\\nb'}) WHERE s.phase IN ['I', 'II'] SET s += {`my key`: null, ok: false} RETURN s, \"$name\""
+	if got != want {
+		t.Fatalf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderReportsMissingParameters(t *testing.T) {
+	t.Parallel()
+
+	_, err := Render("g.V().has('name',$name).limit($limit)", "gremlin", map[string]any{"other": 1})
+	if err == nil || err.Error() != "missing value for parameter(s): name, limit" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unchanged, err := Render("g.V().has('name',$name)", "gremlin", nil)
+	if err != nil || unchanged != "g.V().has('name',$name)" {
+		t.Fatalf("expected query without bindings to pass through, got %q (%v)", unchanged, err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	t.Parallel()
+
+	got := Names("g.V().has('name', $name).has('note', '$quoted').limit($limit).has('alt', $name)")
+	if want := []string{"name", "limit"}; !slices.Equal(got, want) {
+		t.Fatalf("Names() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadFileYAML(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "params.yaml")
+	if err := os.WriteFile(path, []byte("alias: TST-E2-0001\nlimit: 10\ntags: [a, b]\n"), 0o600); err != nil {
+		t.Fatalf("write params: %v", err)
+	}
+
+	bindings, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	got, err := Render("g.V().has('name',$alias).limit($limit).values($tags)", "gremlin", bindings)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got != "g.V().has('name','TST-E2-0001').limit(10).values(['a', 'b'])" {
+		t.Fatalf("unexpected render: %s", got)
+	}
+}
@@ -0,0 +1,160 @@
+// Package result holds the outcome of one Neptune query as decoded values
+// plus the metadata callers report alongside them, so consumers such as
+// schema discovery and e2e verification can use the values directly instead
+// of re-parsing pretty-printed JSON.
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/format"
+	"github.com/ankit-lilly/nqcli/internal/graphson"
+)
+
+// QueryResult is one query's result and metadata.
+type QueryResult struct {
+	// Type is the query language, "gremlin" or "cypher".
+	Type  string
+	Query string
+	// Value is the decoded result: plain JSON values with numbers as
+	// json.Number. It is nil when the payload is not JSON; Text then holds
+	// it as returned, for example an explain plan.
+	Value any
+	Text  string
+	// Raw is the AppSync response body, untouched.
+	Raw string
+	// Duration is the time from sending the query to receiving the response.
+	Duration time.Duration
+	// Bytes is the size of Raw.
+	Bytes int
+	// RequestID is the Neptune request id, when the payload carries one.
+	RequestID string
+	// Warnings are notes about the query, such as schema lint messages,
+	// that did not stop it from running.
+	Warnings []string
+	// CachedAt is when the response was stored in the result cache, for a
+	// result served from it; it is zero for a result fresh from Neptune.
+	CachedAt time.Time
+}
+
+// Options controls how a response is decoded.
+type Options struct {
+	// RawGraphSON keeps GraphSON type wrappers instead of decoding them.
+	RawGraphSON bool
+}
+
+// FromResponse decodes the executeQuery payload of an AppSync response. A
+// response without one is decoded as the payload itself. The returned
+// result carries Raw even when err is not nil.
+func FromResponse(raw string, opts Options) (*QueryResult, error) {
+	var envelope map[string]any
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		r := &QueryResult{Text: raw}
+		r.setRaw(raw)
+		return r, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+	}
+
+	payload := raw
+	if data, ok := envelope["data"].(map[string]any); ok {
+		if executeQuery, ok := data["executeQuery"].(string); ok {
+			payload = executeQuery
+		}
+	}
+	r := FromPayload(payload, opts)
+	r.setRaw(raw)
+	return r, nil
+}
+
+// FromPayload decodes an executeQuery payload: the result JSON, or a
+// {"data": ...} object around it, possibly with a requestId.
+func FromPayload(payload string, opts Options) *QueryResult {
+	value, err := decode(payload, opts)
+	if err != nil {
+		return &QueryResult{Text: payload}
+	}
+
+	r := &QueryResult{}
+	if obj, ok := value.(map[string]any); ok {
+		if id, ok := obj["requestId"].(string); ok {
+			r.RequestID = id
+		}
+		if data, ok := obj["data"]; ok {
+			value = data
+		}
+	}
+	r.Value = value
+	return r
+}
+
+func decode(payload string, opts Options) (any, error) {
+	if !opts.RawGraphSON {
+		return graphson.Unmarshal([]byte(payload))
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(payload)))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+func (r *QueryResult) setRaw(raw string) {
+	r.Raw = raw
+	r.Bytes = len(raw)
+}
+
+// JSON returns the result as indented JSON, or Text when it is not JSON.
+func (r *QueryResult) JSON() string {
+	if r.Value == nil && r.Text != "" {
+		return r.Text
+	}
+	pretty, err := json.MarshalIndent(r.Value, "", "  ")
+	if err != nil {
+		return r.Text
+	}
+	return string(pretty)
+}
+
+// Write renders the result to w in format f. Raw prints the AppSync
+// response, and results that are not JSON are printed unchanged whatever f
+// says.
+func (r *QueryResult) Write(w io.Writer, f format.Format, opts format.Options) error {
+	switch {
+	case f == format.Raw:
+		_, err := fmt.Fprintln(w, r.Raw)
+		return err
+	case f == format.JSON || f == "" || r.Value == nil && r.Text != "":
+		_, err := fmt.Fprintln(w, r.JSON())
+		return err
+	}
+	return format.Write(w, r.Value, f, opts)
+}
+
+// Int64 returns a numeric result, such as the result of count(), unwrapping
+// a single-element list.
+func (r *QueryResult) Int64() (int64, error) {
+	return Int64(r.Value)
+}
+
+// Int64 converts a decoded numeric value, or a single-element list holding
+// one, to an int64.
+func Int64(value any) (int64, error) {
+	if list, ok := value.([]any); ok && len(list) == 1 {
+		value = list[0]
+	}
+	switch v := value.(type) {
+	case json.Number:
+		return v.Int64()
+	case float64:
+		return int64(v), nil
+	case string:
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return parsed, nil
+		}
+	}
+	return 0, fmt.Errorf("unexpected count type %T", value)
+}
@@ -0,0 +1,88 @@
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ankit-lilly/nqcli/internal/format"
+)
+
+func envelope(t *testing.T, payload string) string {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"data": map[string]any{"executeQuery": payload}})
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+	return string(raw)
+}
+
+func TestFromResponseDecodesPayload(t *testing.T) {
+	raw := envelope(t, `{"requestId":"r-1","data":{"@type":"g:List","@value":[{"@type":"g:Int64","@value":42}]}}`)
+
+	res, err := FromResponse(raw, Options{})
+	if err != nil {
+		t.Fatalf("FromResponse: %v", err)
+	}
+	if res.RequestID != "r-1" {
+		t.Fatalf("expected request id r-1, got %q", res.RequestID)
+	}
+	if res.Raw != raw || res.Bytes != len(raw) {
+		t.Fatalf("expected the raw response and its size, got %d bytes", res.Bytes)
+	}
+	count, err := res.Int64()
+	if err != nil || count != 42 {
+		t.Fatalf("expected count 42, got %d (%v)", count, err)
+	}
+	if got := res.JSON(); got != "[\n  42\n]" {
+		t.Fatalf("unexpected JSON: %q", got)
+	}
+}
+
+func TestFromResponseKeepsRawGraphSON(t *testing.T) {
+	res, err := FromResponse(envelope(t, `{"@type":"g:Int64","@value":42}`), Options{RawGraphSON: true})
+	if err != nil {
+		t.Fatalf("FromResponse: %v", err)
+	}
+	if obj, ok := res.Value.(map[string]any); !ok || obj["@type"] != "g:Int64" {
+		t.Fatalf("expected the GraphSON wrapper to be kept, got %#v", res.Value)
+	}
+}
+
+func TestFromResponseRejectsInvalidJSON(t *testing.T) {
+	res, err := FromResponse("<html>bad gateway</html>", Options{})
+	if err == nil {
+		t.Fatalf("expected an error for a non-JSON response")
+	}
+	if res == nil || res.Raw != "<html>bad gateway</html>" {
+		t.Fatalf("expected the result to carry the raw response, got %#v", res)
+	}
+}
+
+func TestWriteTextResultIgnoresFormat(t *testing.T) {
+	res := FromPayload("not json", Options{})
+	if res.Value != nil || res.Text != "not json" {
+		t.Fatalf("expected a text result, got %#v", res)
+	}
+
+	var out bytes.Buffer
+	if err := res.Write(&out, format.CSV, format.Options{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if out.String() != "not json\n" {
+		t.Fatalf("expected text unchanged, got %q", out.String())
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	res := FromPayload(`[{"name":["ABC-123"]},{"name":["DEF-456"]}]`, Options{})
+
+	var out bytes.Buffer
+	if err := res.Write(&out, format.CSV, format.Options{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "name\nABC-123\nDEF-456" {
+		t.Fatalf("unexpected CSV: %q", got)
+	}
+}
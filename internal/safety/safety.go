@@ -0,0 +1,144 @@
+// Package safety classifies Gremlin and openCypher queries as reading or
+// mutating the graph so callers can enforce a read-only mode.
+//
+// Classification is lexical: string literals, comments and quoted identifiers
+// are blanked out and the remaining text is scanned for write steps and
+// clauses. It errs on the side of reporting a write, so an unusual read may be
+// rejected but a write is not let through.
+package safety
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrReadOnly is returned when a mutating query is run in read-only mode.
+var ErrReadOnly = errors.New("read-only mode: query modifies the graph")
+
+// Classification describes what a query does to the graph.
+type Classification struct {
+	// Mutating is true when the query contains at least one write.
+	Mutating bool
+	// Steps lists the write steps or clauses found, in order of first
+	// appearance, for example "drop" or "DELETE".
+	Steps []string
+}
+
+var (
+	gremlinWriteStep  = regexp.MustCompile(`\b(addV|addE|property|drop|mergeV|mergeE)\s*\(`)
+	gremlinSideEffect = regexp.MustCompile(`\bsideEffect\s*[({]`)
+	cypherWriteClause = regexp.MustCompile(`(?i)\b(CREATE|MERGE|SET|DELETE|REMOVE|DROP)\b`)
+)
+
+// Classify inspects query, a Gremlin or openCypher query depending on
+// queryType ("gremlin" or "cypher").
+func Classify(query, queryType string) Classification {
+	if strings.EqualFold(strings.TrimSpace(queryType), "cypher") {
+		return classifyCypher(query)
+	}
+	return classifyGremlin(query)
+}
+
+// IsMutating reports whether query may write to the graph.
+func IsMutating(query, queryType string) bool {
+	return Classify(query, queryType).Mutating
+}
+
+// Check returns an error wrapping ErrReadOnly when query may write to the
+// graph.
+func Check(query, queryType string) error {
+	c := Classify(query, queryType)
+	if !c.Mutating {
+		return nil
+	}
+	return fmt.Errorf("%w (found %s)", ErrReadOnly, strings.Join(c.Steps, ", "))
+}
+
+func classifyGremlin(query string) Classification {
+	code := blankLiterals(query, false)
+
+	var c Classification
+	for _, match := range gremlinWriteStep.FindAllStringSubmatch(code, -1) {
+		c.add(match[1])
+	}
+	// A sideEffect lambda can do anything, so it counts as a write. A
+	// sideEffect(__.drop()) traversal is already caught by the step itself.
+	for _, loc := range gremlinSideEffect.FindAllStringIndex(code, -1) {
+		if code[loc[1]-1] == '{' {
+			c.add("sideEffect")
+		}
+	}
+	return c
+}
+
+func classifyCypher(query string) Classification {
+	code := blankLiterals(query, true)
+
+	var c Classification
+	for _, loc := range cypherWriteClause.FindAllStringIndex(code, -1) {
+		// Skip property keys (n.set), parameters ($delete), labels (:Create)
+		// and map keys ({set: 1}).
+		if loc[0] > 0 && strings.ContainsRune(".$:", rune(code[loc[0]-1])) {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(code[loc[1]:], " \t\r\n"), ":") {
+			continue
+		}
+		c.add(strings.ToUpper(code[loc[0]:loc[1]]))
+	}
+	return c
+}
+
+func (c *Classification) add(step string) {
+	c.Mutating = true
+	if !slices.Contains(c.Steps, step) {
+		c.Steps = append(c.Steps, step)
+	}
+}
+
+// blankLiterals replaces the contents of string literals, comments and (for
+// Cypher) backtick-quoted identifiers with spaces, keeping offsets intact.
+func blankLiterals(query string, cypher bool) string {
+	out := []byte(query)
+	blank := func(from, to int) {
+		for i := from; i < to && i < len(out); i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(out); i++ {
+		switch ch := out[i]; {
+		case ch == '\'' || ch == '"' || (cypher && ch == '`'):
+			j := i + 1
+			for j < len(out) && out[j] != ch {
+				if out[j] == '\\' && ch != '`' {
+					j++
+				}
+				j++
+			}
+			blank(i+1, j)
+			i = j
+		case ch == '/' && i+1 < len(out) && out[i+1] == '/':
+			j := i
+			for j < len(out) && out[j] != '\n' {
+				j++
+			}
+			blank(i, j)
+			i = j
+		case ch == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := strings.Index(string(out[i+2:]), "*/")
+			j := len(out)
+			if end >= 0 {
+				j = i + 2 + end + 2
+			}
+			blank(i, j)
+			i = j - 1
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,69 @@
+package safety
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestClassifyGremlin(t *testing.T) {
+	tests := []struct {
+		query string
+		steps []string
+	}{
+		{query: `g.V().hasLabel('Study').valueMap()`},
+		{query: `g.V().properties('name').value()`},
+		{query: `g.V().has('name', 'drop(everything)')`},
+		{query: `g.V().has("note", "call addV(")`},
+		{query: `g.V() // .drop()`},
+		{query: `g.V().drop()`, steps: []string{"drop"}},
+		{query: `g.addV('Person').property('name', 'x')`, steps: []string{"addV", "property"}},
+		{query: `g.V(1).addE('knows').to(__.V(2))`, steps: []string{"addE"}},
+		{query: `g.mergeV([(T.label): 'Study']).option(onCreate, [:])`, steps: []string{"mergeV"}},
+		{query: `g.V().sideEffect(__.drop())`, steps: []string{"drop"}},
+		{query: `g.V().sideEffect{ it.get().remove() }`, steps: []string{"sideEffect"}},
+		{query: `g.V().has('name', 'it\'s').drop ()`, steps: []string{"drop"}},
+	}
+
+	for _, tt := range tests {
+		got := Classify(tt.query, "gremlin")
+		if got.Mutating != (len(tt.steps) > 0) || !slices.Equal(got.Steps, tt.steps) {
+			t.Fatalf("Classify(%q) = %+v, want steps %v", tt.query, got, tt.steps)
+		}
+	}
+}
+
+func TestClassifyCypher(t *testing.T) {
+	tests := []struct {
+		query string
+		steps []string
+	}{
+		{query: `MATCH (s:Study) RETURN s.name LIMIT 5`},
+		{query: `MATCH (n) WHERE n.set = 1 RETURN n`},
+		{query: `MATCH (n:Create) RETURN n {set: n.delete}`},
+		{query: "MATCH (n) WHERE n.`create` = 'DELETE me' RETURN n"},
+		{query: `MATCH (n) WHERE n.id = $remove RETURN n`},
+		{query: `CREATE (n:Person {name: 'x'})`, steps: []string{"CREATE"}},
+		{query: `MATCH (n) DETACH DELETE n`, steps: []string{"DELETE"}},
+		{query: `merge (n:Study {id: 1}) on create set n.created = 1`, steps: []string{"MERGE", "CREATE", "SET"}},
+		{query: `MATCH (n) REMOVE n.flag`, steps: []string{"REMOVE"}},
+	}
+
+	for _, tt := range tests {
+		got := Classify(tt.query, "cypher")
+		if got.Mutating != (len(tt.steps) > 0) || !slices.Equal(got.Steps, tt.steps) {
+			t.Fatalf("Classify(%q) = %+v, want steps %v", tt.query, got, tt.steps)
+		}
+	}
+}
+
+func TestCheckWrapsErrReadOnly(t *testing.T) {
+	if err := Check("g.V().count()", "gremlin"); err != nil {
+		t.Fatalf("expected read query to pass, got %v", err)
+	}
+
+	err := Check("g.V().drop()", "gremlin")
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
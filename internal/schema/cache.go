@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cacheVersion     = 2
+	cacheTTLEnvVar   = "NQ_SCHEMA_CACHE_TTL"
+	defaultCacheTTL  = 24 * time.Hour
+	cacheFileName    = "schema_cache.json"
+	cacheTempPattern = "schema-cache-*.json"
+)
+
+// endpointProvider is implemented by executors that know which AppSync
+// endpoint they talk to. Schemas are only cached when the endpoint is known.
+type endpointProvider interface {
+	Endpoint() string
+}
+
+type cacheFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]*cacheEntry `json:"entries"`
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Schema    *Graph    `json:"schema"`
+}
+
+// Cache memoizes discovered schemas per endpoint for the life of the process
+// and persists them under the user cache directory for NQ_SCHEMA_CACHE_TTL.
+type Cache struct {
+	// Refresh forces the next discovery per endpoint to bypass the disk
+	// cache, as requested with --refresh-schema.
+	Refresh bool
+
+	// discoverMu serializes discoveries, so concurrent tool calls do not run
+	// the same expensive queries twice. Cached never takes it.
+	discoverMu sync.Mutex
+
+	// mu guards the maps below and is only held briefly.
+	mu        sync.RWMutex
+	memo      map[string]*cacheEntry
+	refreshed map[string]bool
+	// diskRead records endpoints whose disk entry Cached has already read.
+	diskRead map[string]bool
+
+	path func() (string, error)
+	ttl  func() (time.Duration, error)
+	now  func() time.Time
+}
+
+// NewCache returns an empty cache backed by the user cache directory.
+func NewCache() *Cache {
+	return &Cache{
+		memo:      make(map[string]*cacheEntry),
+		refreshed: make(map[string]bool),
+		diskRead:  make(map[string]bool),
+		path:      cachePath,
+		ttl:       cacheTTL,
+		now:       time.Now,
+	}
+}
+
+// Discover returns the schema for exec's endpoint from memory, then disk, and
+// only calls discover when both are missing or stale.
+func (c *Cache) Discover(ctx context.Context, exec Executor, discover func(context.Context) (*Graph, error)) (*Graph, error) {
+	endpoint := ""
+	if provider, ok := exec.(endpointProvider); ok {
+		endpoint = provider.Endpoint()
+	}
+	ttl, err := c.ttl()
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == "" || ttl <= 0 {
+		return discover(ctx)
+	}
+
+	c.discoverMu.Lock()
+	defer c.discoverMu.Unlock()
+
+	c.mu.RLock()
+	bypassDisk := c.Refresh && !c.refreshed[endpoint]
+	memo := c.memo[endpoint]
+	c.mu.RUnlock()
+	if memo != nil && c.fresh(memo, ttl) {
+		return memo.Schema, nil
+	}
+	if !bypassDisk {
+		if entry := c.readEntry(endpoint); entry != nil && c.fresh(entry, ttl) {
+			c.remember(endpoint, entry, false)
+			return entry.Schema, nil
+		}
+	}
+
+	schema, err := discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Partial results are served but not kept, so the next call retries the
+	// queries that failed.
+	if len(schema.Warnings) > 0 {
+		return schema, nil
+	}
+	entry := &cacheEntry{FetchedAt: c.now(), Schema: schema}
+	c.remember(endpoint, entry, true)
+	// A cache that cannot be written only costs a slower next start.
+	_ = c.writeEntry(endpoint, entry)
+	return schema, nil
+}
+
+// Cached returns the schema for exec's endpoint when a fresh one is in memory
+// or on disk, and nil otherwise. Unlike Discover it never queries the graph
+// and never waits for a discovery in progress; the disk cache is read at most
+// once per endpoint.
+func (c *Cache) Cached(exec Executor) *Graph {
+	provider, ok := exec.(endpointProvider)
+	if !ok || provider.Endpoint() == "" {
+		return nil
+	}
+	endpoint := provider.Endpoint()
+	ttl, err := c.ttl()
+	if err != nil || ttl <= 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	memo, diskRead := c.memo[endpoint], c.diskRead[endpoint]
+	c.mu.RUnlock()
+	if memo != nil && c.fresh(memo, ttl) {
+		return memo.Schema
+	}
+	if diskRead {
+		return nil
+	}
+
+	entry := c.readEntry(endpoint)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diskRead[endpoint] = true
+	if entry == nil || !c.fresh(entry, ttl) {
+		return nil
+	}
+	if current := c.memo[endpoint]; current == nil || current.FetchedAt.Before(entry.FetchedAt) {
+		c.memo[endpoint] = entry
+	}
+	return entry.Schema
+}
+
+// remember memoizes entry for endpoint; refreshed marks a discovery that
+// satisfies --refresh-schema.
+func (c *Cache) remember(endpoint string, entry *cacheEntry, refreshed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memo[endpoint] = entry
+	c.diskRead[endpoint] = true
+	if refreshed {
+		c.refreshed[endpoint] = true
+	}
+}
+
+func (c *Cache) fresh(entry *cacheEntry, ttl time.Duration) bool {
+	return entry.Schema != nil && c.now().Sub(entry.FetchedAt) < ttl
+}
+
+func (c *Cache) readEntry(endpoint string) *cacheEntry {
+	file, err := c.readFile()
+	if err != nil {
+		return nil
+	}
+	return file.Entries[endpoint]
+}
+
+func (c *Cache) readFile() (*cacheFile, error) {
+	empty := &cacheFile{Version: cacheVersion, Entries: map[string]*cacheEntry{}}
+
+	path, err := c.path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return empty, nil
+		}
+		return nil, err
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != cacheVersion {
+		return empty, nil
+	}
+	if file.Entries == nil {
+		file.Entries = map[string]*cacheEntry{}
+	}
+	return &file, nil
+}
+
+func (c *Cache) writeEntry(endpoint string, entry *cacheEntry) error {
+	file, err := c.readFile()
+	if err != nil {
+		file = &cacheFile{Version: cacheVersion, Entries: map[string]*cacheEntry{}}
+	}
+	file.Entries[endpoint] = entry
+
+	path, err := c.path()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, cacheTempPattern)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func cachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nqcli", cacheFileName), nil
+}
+
+// cacheTTL reads NQ_SCHEMA_CACHE_TTL; 0 disables caching.
+func cacheTTL() (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(cacheTTLEnvVar))
+	if value == "" {
+		return defaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", cacheTTLEnvVar, value, err)
+	}
+	return ttl, nil
+}
@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type endpointExecutor struct {
+	scriptedGraphService
+	endpoint string
+}
+
+func (s *endpointExecutor) Endpoint() string { return s.endpoint }
+
+func newTestCache(path string, now *time.Time) *Cache {
+	cache := NewCache()
+	cache.path = func() (string, error) { return path, nil }
+	cache.ttl = func() (time.Duration, error) { return time.Hour, nil }
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func TestCacheMemoizesAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema_cache.json")
+	now := time.Now()
+	service := &endpointExecutor{endpoint: "https://example.appsync-api.us-east-2.amazonaws.com/graphql"}
+
+	calls := 0
+	discover := func(context.Context) (*Graph, error) {
+		calls++
+		return &Graph{SchemaVersion: "dynamic", VertexLabels: []string{"Study"}}, nil
+	}
+
+	cache := newTestCache(path, &now)
+	for range 2 {
+		schema, err := cache.Discover(context.Background(), service, discover)
+		if err != nil {
+			t.Fatalf("discover: %v", err)
+		}
+		if len(schema.VertexLabels) != 1 {
+			t.Fatalf("unexpected schema %+v", schema)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected in-process memo to avoid rediscovery, got %d discoveries", calls)
+	}
+
+	// A new process reads the disk cache.
+	if _, err := newTestCache(path, &now).Discover(context.Background(), service, discover); err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected disk cache hit, got %d discoveries", calls)
+	}
+
+	// --refresh-schema bypasses the disk once.
+	refreshing := newTestCache(path, &now)
+	refreshing.Refresh = true
+	for range 2 {
+		if _, err := refreshing.Discover(context.Background(), service, discover); err != nil {
+			t.Fatalf("discover: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected exactly one refresh, got %d discoveries", calls)
+	}
+
+	// Entries expire after the TTL.
+	now = now.Add(2 * time.Hour)
+	if _, err := newTestCache(path, &now).Discover(context.Background(), service, discover); err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected stale entry to be rediscovered, got %d discoveries", calls)
+	}
+}
+
+func TestCacheSkipsServicesWithoutEndpoint(t *testing.T) {
+	now := time.Now()
+	cache := newTestCache(filepath.Join(t.TempDir(), "schema_cache.json"), &now)
+
+	calls := 0
+	discover := func(context.Context) (*Graph, error) {
+		calls++
+		return &Graph{}, nil
+	}
+	for range 2 {
+		if _, err := cache.Discover(context.Background(), &scriptedGraphService{}, discover); err != nil {
+			t.Fatalf("discover: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected no caching without an endpoint, got %d discoveries", calls)
+	}
+}
+
+func TestCacheCachedNeverDiscovers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema_cache.json")
+	now := time.Now()
+	service := &endpointExecutor{endpoint: "https://example.appsync-api.us-east-2.amazonaws.com/graphql"}
+
+	if got := newTestCache(path, &now).Cached(service); got != nil {
+		t.Fatalf("expected nothing cached, got %+v", got)
+	}
+	discover := func(context.Context) (*Graph, error) {
+		return &Graph{SchemaVersion: "dynamic", VertexLabels: []string{"Study"}}, nil
+	}
+	if _, err := newTestCache(path, &now).Discover(context.Background(), service, discover); err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if got := newTestCache(path, &now).Cached(service); got == nil || len(got.VertexLabels) != 1 {
+		t.Fatalf("expected the persisted schema, got %+v", got)
+	}
+	if service.maxInFlight != 0 {
+		t.Fatalf("expected no queries, saw %d", service.maxInFlight)
+	}
+}
+
+func TestCacheCachedDoesNotWaitForDiscovery(t *testing.T) {
+	now := time.Now()
+	cache := newTestCache(filepath.Join(t.TempDir(), "schema_cache.json"), &now)
+	service := &endpointExecutor{endpoint: "https://example.appsync-api.us-east-2.amazonaws.com/graphql"}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.Discover(context.Background(), service, func(context.Context) (*Graph, error) {
+			close(started)
+			<-release
+			return &Graph{SchemaVersion: "dynamic"}, nil
+		})
+	}()
+	<-started
+
+	answered := make(chan *Graph, 1)
+	go func() { answered <- cache.Cached(service) }()
+	select {
+	case got := <-answered:
+		if got != nil {
+			t.Fatalf("expected nothing cached during discovery, got %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Cached blocked on an in-flight discovery")
+	}
+
+	close(release)
+	<-done
+	if cache.Cached(service) == nil {
+		t.Fatalf("expected the discovered schema once discovery finished")
+	}
+}
+
+func TestCacheCachedReadsDiskOnce(t *testing.T) {
+	now := time.Now()
+	cache := newTestCache(filepath.Join(t.TempDir(), "schema_cache.json"), &now)
+	reads := 0
+	path := cache.path
+	cache.path = func() (string, error) {
+		reads++
+		return path()
+	}
+	service := &endpointExecutor{endpoint: "https://example.appsync-api.us-east-2.amazonaws.com/graphql"}
+
+	for range 3 {
+		if got := cache.Cached(service); got != nil {
+			t.Fatalf("expected nothing cached, got %+v", got)
+		}
+	}
+	if reads != 1 {
+		t.Fatalf("expected the disk cache to be read once, got %d reads", reads)
+	}
+}
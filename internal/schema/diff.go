@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Diff lists the differences between an expected schema, usually the static
+// one, and an actual schema discovered from the graph. Missing entries are
+// declared but were not found in the graph; extra entries were found in the
+// graph but are not declared.
+type Diff struct {
+	MissingVertexLabels []string       `json:"missing_vertex_labels,omitempty"`
+	ExtraVertexLabels   []string       `json:"extra_vertex_labels,omitempty"`
+	MissingEdgeLabels   []string       `json:"missing_edge_labels,omitempty"`
+	ExtraEdgeLabels     []string       `json:"extra_edge_labels,omitempty"`
+	MissingEdgePatterns []EdgePattern  `json:"missing_edge_patterns,omitempty"`
+	ExtraEdgePatterns   []EdgePattern  `json:"extra_edge_patterns,omitempty"`
+	Properties          []PropertyDiff `json:"properties,omitempty"`
+}
+
+// PropertyDiff lists the property keys that differ for a vertex label known
+// to both schemas.
+type PropertyDiff struct {
+	Label   string   `json:"label"`
+	Missing []string `json:"missing,omitempty"`
+	Extra   []string `json:"extra,omitempty"`
+}
+
+// Compare diffs expected against actual. Edge patterns are compared by their
+// labels only, since discovery cannot see document field names. Properties
+// are compared for vertex labels present in both schemas.
+func Compare(expected, actual *Graph) *Diff {
+	d := &Diff{}
+	d.MissingVertexLabels, d.ExtraVertexLabels = diffStrings(expected.VertexLabels, actual.VertexLabels)
+	d.MissingEdgeLabels, d.ExtraEdgeLabels = diffStrings(expected.EdgeLabels, actual.EdgeLabels)
+	d.MissingEdgePatterns = subtractPatterns(expected.EdgePatterns, actual.EdgePatterns)
+	d.ExtraEdgePatterns = subtractPatterns(actual.EdgePatterns, expected.EdgePatterns)
+
+	for _, label := range expected.VertexLabels {
+		if !slices.Contains(actual.VertexLabels, label) {
+			continue
+		}
+		missing, extra := diffStrings(propertyNames(expected.Vertices[label]), propertyNames(actual.Vertices[label]))
+		if len(missing) > 0 || len(extra) > 0 {
+			d.Properties = append(d.Properties, PropertyDiff{Label: label, Missing: missing, Extra: extra})
+		}
+	}
+	return d
+}
+
+// HasMissing reports whether anything declared was not found in the graph.
+func (d *Diff) HasMissing() bool {
+	if len(d.MissingVertexLabels) > 0 || len(d.MissingEdgeLabels) > 0 || len(d.MissingEdgePatterns) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(d.Properties, func(p PropertyDiff) bool { return len(p.Missing) > 0 })
+}
+
+// HasExtra reports whether the graph holds anything that is not declared.
+func (d *Diff) HasExtra() bool {
+	if len(d.ExtraVertexLabels) > 0 || len(d.ExtraEdgeLabels) > 0 || len(d.ExtraEdgePatterns) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(d.Properties, func(p PropertyDiff) bool { return len(p.Extra) > 0 })
+}
+
+// Empty reports whether the schemas match.
+func (d *Diff) Empty() bool {
+	return !d.HasMissing() && !d.HasExtra()
+}
+
+// WriteText renders the diff for people: "-" marks entries missing from the
+// graph and "+" entries missing from the declared schema.
+func (d *Diff) WriteText(w io.Writer) error {
+	if d.Empty() {
+		_, err := io.WriteString(w, "No schema drift.\n")
+		return err
+	}
+
+	var b strings.Builder
+	writeSection := func(title string, items []string, mark string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", title, len(items))
+		for _, item := range items {
+			fmt.Fprintf(&b, "  %s %s\n", mark, item)
+		}
+		b.WriteString("\n")
+	}
+	writeSection("Vertex labels missing from the graph", d.MissingVertexLabels, "-")
+	writeSection("Vertex labels not in the schema", d.ExtraVertexLabels, "+")
+	writeSection("Edge labels missing from the graph", d.MissingEdgeLabels, "-")
+	writeSection("Edge labels not in the schema", d.ExtraEdgeLabels, "+")
+	writeSection("Edge patterns missing from the graph", formatPatterns(d.MissingEdgePatterns), "-")
+	writeSection("Edge patterns not in the schema", formatPatterns(d.ExtraEdgePatterns), "+")
+
+	if len(d.Properties) > 0 {
+		fmt.Fprintf(&b, "Vertex properties (%d labels):\n", len(d.Properties))
+		for _, prop := range d.Properties {
+			fmt.Fprintf(&b, "  %s\n", prop.Label)
+			for _, name := range prop.Missing {
+				fmt.Fprintf(&b, "    - %s\n", name)
+			}
+			for _, name := range prop.Extra {
+				fmt.Fprintf(&b, "    + %s\n", name)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, strings.TrimSuffix(b.String(), "\n"))
+	return err
+}
+
+// diffStrings returns the sorted, de-duplicated items only in want and only
+// in got.
+func diffStrings(want, got []string) (missing, extra []string) {
+	for _, item := range want {
+		if !slices.Contains(got, item) {
+			missing = append(missing, item)
+		}
+	}
+	for _, item := range got {
+		if !slices.Contains(want, item) {
+			extra = append(extra, item)
+		}
+	}
+	slices.Sort(missing)
+	slices.Sort(extra)
+	return slices.Compact(missing), slices.Compact(extra)
+}
+
+func subtractPatterns(from, other []EdgePattern) []EdgePattern {
+	seen := make(map[EdgePattern]bool, len(other))
+	for _, pattern := range other {
+		seen[patternKey(pattern)] = true
+	}
+	var out []EdgePattern
+	for _, pattern := range from {
+		key := patternKey(pattern)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, pattern)
+	}
+	slices.SortFunc(out, func(a, b EdgePattern) int {
+		return strings.Compare(formatPattern(a), formatPattern(b))
+	})
+	return out
+}
+
+func patternKey(pattern EdgePattern) EdgePattern {
+	pattern.Field = ""
+	return pattern
+}
+
+func formatPatterns(patterns []EdgePattern) []string {
+	out := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		out[i] = formatPattern(pattern)
+	}
+	return out
+}
+
+func formatPattern(pattern EdgePattern) string {
+	return fmt.Sprintf("%s -[%s]-> %s", pattern.Out, pattern.Label, pattern.In)
+}
+
+func propertyNames(label Label) []string {
+	names := make([]string, len(label.Properties))
+	for i, prop := range label.Properties {
+		names[i] = prop.Name
+	}
+	return names
+}
@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompareReportsMissingAndExtra(t *testing.T) {
+	expected := &Graph{
+		VertexLabels: []string{"Study", "StudyVersion", "StudyRole"},
+		EdgeLabels:   []string{"has_role", "has_version"},
+		EdgePatterns: []EdgePattern{
+			{Out: "Study", Label: "has_version", In: "StudyVersion", Field: "versions"},
+			{Out: "StudyVersion", Label: "has_role", In: "StudyRole", Field: "roles"},
+		},
+		Vertices: map[string]Label{
+			"Study":        {Properties: []Property{{Name: "id"}, {Name: "name"}}},
+			"StudyVersion": {Properties: []Property{{Name: "id"}}},
+		},
+	}
+	actual := &Graph{
+		VertexLabels: []string{"Study", "StudyVersion", "Audit"},
+		EdgeLabels:   []string{"has_version", "has_versions"},
+		EdgePatterns: []EdgePattern{
+			{Out: "Study", Label: "has_version", In: "StudyVersion"},
+			{Out: "Study", Label: "has_versions", In: "StudyVersion"},
+		},
+		Vertices: map[string]Label{
+			"Study":        {Properties: []Property{{Name: "name"}, {Name: "phase"}}},
+			"StudyVersion": {Properties: []Property{{Name: "id"}}},
+		},
+	}
+
+	d := Compare(expected, actual)
+	if !d.HasMissing() || !d.HasExtra() || d.Empty() {
+		t.Fatalf("expected missing and extra entries, got %+v", d)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := `Vertex labels missing from the graph (1):
+  - StudyRole
+
+Vertex labels not in the schema (1):
+  + Audit
+
+Edge labels missing from the graph (1):
+  - has_role
+
+Edge labels not in the schema (1):
+  + has_versions
+
+Edge patterns missing from the graph (1):
+  - StudyVersion -[has_role]-> StudyRole
+
+Edge patterns not in the schema (1):
+  + Study -[has_versions]-> StudyVersion
+
+Vertex properties (1 labels):
+  Study
+    - id
+    + phase
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestCompareStaticWithItself(t *testing.T) {
+	doc, err := ParseStatic()
+	if err != nil {
+		t.Fatalf("ParseStatic: %v", err)
+	}
+	d := Compare(doc.Graph(), doc.Graph())
+	if !d.Empty() {
+		t.Fatalf("expected no drift, got %+v", d)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "No schema drift.") {
+		t.Fatalf("unexpected report %q", buf.String())
+	}
+}
@@ -0,0 +1,457 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/graphson"
+	"github.com/ankit-lilly/nqcli/internal/params"
+	"github.com/ankit-lilly/nqcli/internal/result"
+)
+
+const (
+	concurrencyEnvVar     = "NQ_SCHEMA_CONCURRENCY"
+	queryTimeoutEnvVar    = "NQ_SCHEMA_QUERY_TIMEOUT"
+	sampleSizeEnvVar      = "NQ_SCHEMA_SAMPLE_SIZE"
+	defaultConcurrency    = 8
+	defaultQueryTimeout   = 30 * time.Second
+	defaultSampleSize     = 100
+	maxDiscoveryWarnings  = 50
+	discoveryWarningsTail = "further warnings omitted"
+	enumSampleLimit       = 10
+	enumReturnLimit       = 10
+	enumSampleValueLimit  = 5
+)
+
+// Executor runs a query and returns its decoded result.
+type Executor interface {
+	ExecuteQueryContext(ctx context.Context, query, queryType string) (*result.QueryResult, error)
+}
+
+// Options tunes dynamic schema discovery.
+type Options struct {
+	// Concurrency bounds the number of discovery queries in flight.
+	Concurrency int
+	// QueryTimeout bounds each discovery query; 0 means no per-query limit.
+	QueryTimeout time.Duration
+	// SampleSize is the number of elements per label whose property values
+	// are profiled for types, presence and ranges; 0 skips profiling.
+	SampleSize int
+	// Progress, when set, is called as queries finish with the number of
+	// finished queries and the number known so far. The total grows as
+	// labels and properties are found. Calls never overlap and neither
+	// count decreases between calls.
+	Progress func(done, total int)
+}
+
+// OptionsFromEnv reads NQ_SCHEMA_CONCURRENCY, NQ_SCHEMA_QUERY_TIMEOUT and
+// NQ_SCHEMA_SAMPLE_SIZE on top of the defaults.
+func OptionsFromEnv() (Options, error) {
+	opts := Options{
+		Concurrency:  defaultConcurrency,
+		QueryTimeout: defaultQueryTimeout,
+		SampleSize:   defaultSampleSize,
+	}
+	if value := strings.TrimSpace(os.Getenv(concurrencyEnvVar)); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return opts, fmt.Errorf("invalid %s %q: must be a positive integer", concurrencyEnvVar, value)
+		}
+		opts.Concurrency = parsed
+	}
+	if value := strings.TrimSpace(os.Getenv(queryTimeoutEnvVar)); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return opts, fmt.Errorf("invalid %s %q: must be a non-negative duration", queryTimeoutEnvVar, value)
+		}
+		opts.QueryTimeout = parsed
+	}
+	if value := strings.TrimSpace(os.Getenv(sampleSizeEnvVar)); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return opts, fmt.Errorf("invalid %s %q: must be a non-negative integer", sampleSizeEnvVar, value)
+		}
+		opts.SampleSize = parsed
+	}
+	return opts, nil
+}
+
+// Discover queries the live graph for labels, properties, edge patterns,
+// counts, enum candidates and sampled property profiles. Label and property work fans out over
+// opts.Concurrency workers, each query bounded by opts.QueryTimeout. Only a
+// failure to list vertex or edge labels is fatal; other failures leave the
+// affected entry incomplete and are recorded in the schema's warnings.
+func Discover(ctx context.Context, exec Executor, opts Options) (*Graph, error) {
+	d := newDiscovery(exec, opts)
+
+	var (
+		vertexLabels, edgeLabels []string
+		edgePatterns             []EdgePattern
+	)
+	errs := d.run(ctx, []discoveryTask{
+		{"vertex labels", func(ctx context.Context) (err error) {
+			vertexLabels, err = queryStringList(ctx, exec, "g.V().label().dedup()")
+			return err
+		}},
+		{"edge labels", func(ctx context.Context) (err error) {
+			edgeLabels, err = queryStringList(ctx, exec, "g.E().label().dedup()")
+			return err
+		}},
+		{"edge patterns", func(ctx context.Context) (err error) {
+			edgePatterns, err = queryEdgePatterns(ctx, exec)
+			return err
+		}},
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if errs[0] != nil {
+		return nil, fmt.Errorf("discover vertex labels: %w", errs[0])
+	}
+	if errs[1] != nil {
+		return nil, fmt.Errorf("discover edge labels: %w", errs[1])
+	}
+	d.warn("discover edge patterns", errs[2])
+
+	slices.Sort(vertexLabels)
+	slices.Sort(edgeLabels)
+
+	vertices, err := d.discoverLabels(ctx, "g.V()", "vertex", vertexLabels)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := d.discoverLabels(ctx, "g.E()", "edge", edgeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Graph{
+		SchemaVersion: string(Dynamic),
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		VertexLabels:  vertexLabels,
+		EdgeLabels:    edgeLabels,
+		EdgePatterns:  edgePatterns,
+		Vertices:      vertices,
+		Edges:         edges,
+		Warnings:      d.warnings,
+	}, nil
+}
+
+type discoveryTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// discovery runs discovery queries on a bounded pool shared by every phase
+// and collects warnings for the queries that failed.
+type discovery struct {
+	exec  Executor
+	opts  Options
+	slots chan struct{}
+
+	mu       sync.Mutex
+	done     int
+	total    int
+	warnings []string
+
+	// progressMu serializes Progress callbacks; reportedDone and
+	// reportedTotal are the last counts passed to them.
+	progressMu                  sync.Mutex
+	reportedDone, reportedTotal int
+}
+
+func newDiscovery(exec Executor, opts Options) *discovery {
+	return &discovery{
+		exec:  exec,
+		opts:  opts,
+		slots: make(chan struct{}, max(opts.Concurrency, 1)),
+	}
+}
+
+// run executes tasks concurrently and returns their errors by index. Tasks
+// that have not started when ctx is done fail with ctx.Err().
+func (d *discovery) run(ctx context.Context, tasks []discoveryTask) []error {
+	d.mu.Lock()
+	d.total += len(tasks)
+	d.mu.Unlock()
+
+	errs := make([]error, len(tasks))
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		select {
+		case d.slots <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(tasks); j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-d.slots }()
+
+			taskCtx := ctx
+			if d.opts.QueryTimeout > 0 {
+				var cancel context.CancelFunc
+				taskCtx, cancel = context.WithTimeout(ctx, d.opts.QueryTimeout)
+				defer cancel()
+			}
+			errs[i] = task.run(taskCtx)
+			d.finished()
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// finished counts a completed query and reports progress. The callback runs
+// without d.mu held, so a slow one does not stall the other workers; it is
+// still serialized, and counts that arrive out of order are skipped so that
+// it never sees them go backwards.
+func (d *discovery) finished() {
+	d.mu.Lock()
+	d.done++
+	done, total := d.done, d.total
+	d.mu.Unlock()
+
+	if d.opts.Progress == nil {
+		return
+	}
+	d.progressMu.Lock()
+	defer d.progressMu.Unlock()
+	if done <= d.reportedDone {
+		return
+	}
+	d.reportedDone, d.reportedTotal = done, max(total, d.reportedTotal)
+	d.opts.Progress(d.reportedDone, d.reportedTotal)
+}
+
+// warn records err against step. Cancellation of the whole discovery is not a
+// warning; callers check the parent context instead.
+func (d *discovery) warn(step string, err error) {
+	if err == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case len(d.warnings) < maxDiscoveryWarnings:
+		d.warnings = append(d.warnings, fmt.Sprintf("%s: %v", step, err))
+	case len(d.warnings) == maxDiscoveryWarnings:
+		d.warnings = append(d.warnings, discoveryWarningsTail)
+	}
+}
+
+// discoverLabels collects property keys and counts for every label, then
+// looks up enum candidates and profiles a sample of values for every
+// property, each step in parallel.
+func (d *discovery) discoverLabels(ctx context.Context, source, kind string, labels []string) (map[string]Label, error) {
+	props := make([][]string, len(labels))
+	counts := make([]int64, len(labels))
+
+	tasks := make([]discoveryTask, 0, 2*len(labels))
+	for i, label := range labels {
+		quoted := params.QuoteGremlin(label)
+		tasks = append(tasks,
+			discoveryTask{"discover " + kind + " properties for " + label, func(ctx context.Context) (err error) {
+				props[i], err = queryStringList(ctx, d.exec, fmt.Sprintf("%s.hasLabel(%s).properties().key().dedup()", source, quoted))
+				return err
+			}},
+			discoveryTask{"count " + kind + " " + label, func(ctx context.Context) (err error) {
+				counts[i], err = queryCount(ctx, d.exec, fmt.Sprintf("%s.hasLabel(%s).count()", source, quoted))
+				return err
+			}},
+		)
+	}
+	for i, err := range d.run(ctx, tasks) {
+		d.warn(tasks[i].name, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	infos := make([][]Property, len(labels))
+	tasks = tasks[:0]
+	for i, label := range labels {
+		slices.Sort(props[i])
+		infos[i] = make([]Property, len(props[i]))
+		for j, prop := range props[i] {
+			infos[i][j] = Property{Name: prop}
+			tasks = append(tasks, discoveryTask{"analyze " + kind + " property " + label + "." + prop, func(ctx context.Context) error {
+				values, err := queryEnumCandidates(ctx, d.exec, source, label, prop)
+				if err != nil {
+					return err
+				}
+				info := newProperty(prop, values)
+				infos[i][j] = info
+				if d.opts.SampleSize <= 0 {
+					return nil
+				}
+				samples, err := queryPropertySample(ctx, d.exec, source, label, prop, d.opts.SampleSize)
+				if err != nil {
+					return fmt.Errorf("sample values: %w", err)
+				}
+				profileProperty(&info, samples)
+				// A full sample saw every element; otherwise the label may
+				// still have elements without the property.
+				if info.AlwaysPresent && len(samples) >= d.opts.SampleSize {
+					info.AlwaysPresent, err = queryAlwaysPresent(ctx, d.exec, source, label, prop)
+					if err != nil {
+						infos[i][j] = info
+						return fmt.Errorf("check presence: %w", err)
+					}
+				}
+				infos[i][j] = info
+				return nil
+			}})
+		}
+	}
+	for i, err := range d.run(ctx, tasks) {
+		d.warn(tasks[i].name, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	labelSchemas := make(map[string]Label, len(labels))
+	for i, label := range labels {
+		labelSchemas[label] = Label{
+			Count:      counts[i],
+			Properties: infos[i],
+		}
+	}
+	return labelSchemas, nil
+}
+
+func newProperty(prop string, values []any) Property {
+	info := Property{Name: prop}
+	if len(values) > 0 {
+		info.SampleValues = values[:min(len(values), enumSampleValueLimit)]
+	}
+	if len(values) > 0 && len(values) <= enumReturnLimit {
+		info.Enum = values
+	}
+	return info
+}
+
+func queryEnumCandidates(ctx context.Context, exec Executor, source, label, prop string) ([]any, error) {
+	query := fmt.Sprintf(
+		"%s.hasLabel(%s).values(%s).dedup().limit(%d)",
+		source,
+		params.QuoteGremlin(label),
+		params.QuoteGremlin(prop),
+		enumSampleLimit+1,
+	)
+	values, err := queryAnyList(ctx, exec, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > enumSampleLimit {
+		return nil, nil
+	}
+	return values, nil
+}
+
+func queryStringList(ctx context.Context, exec Executor, query string) ([]string, error) {
+	raw, err := executeGremlin(ctx, exec, query)
+	if err != nil {
+		return nil, err
+	}
+	return asStringSlice(raw)
+}
+
+func queryAnyList(ctx context.Context, exec Executor, query string) ([]any, error) {
+	raw, err := executeGremlin(ctx, exec, query)
+	if err != nil {
+		return nil, err
+	}
+	return asAnySlice(raw)
+}
+
+func queryEdgePatterns(ctx context.Context, exec Executor) ([]EdgePattern, error) {
+	raw, err := executeGremlin(ctx, exec, "g.E().project('out','label','in').by(outV().label()).by(label()).by(inV().label()).dedup()")
+	if err != nil {
+		return nil, err
+	}
+	anyList, err := asAnySlice(raw)
+	if err != nil {
+		return nil, err
+	}
+	patterns := make([]EdgePattern, 0, len(anyList))
+	for _, item := range anyList {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		outVal, outOk := m["out"].(string)
+		labelVal, labelOk := m["label"].(string)
+		inVal, inOk := m["in"].(string)
+		if outOk && labelOk && inOk {
+			patterns = append(patterns, EdgePattern{Out: outVal, Label: labelVal, In: inVal})
+		}
+	}
+	return patterns, nil
+}
+
+func queryCount(ctx context.Context, exec Executor, query string) (int64, error) {
+	value, err := executeGremlin(ctx, exec, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.Int64(value)
+}
+
+func executeGremlin(ctx context.Context, exec Executor, query string) (any, error) {
+	res, err := exec.ExecuteQueryContext(ctx, query, "gremlin")
+	if err != nil {
+		return nil, err
+	}
+	if res.Value == nil && res.Text != "" {
+		return nil, fmt.Errorf("parse gremlin response: not JSON: %.80s", res.Text)
+	}
+	// Decode GraphSON here too, so discovery works with --raw-graphson.
+	return graphson.Decode(res.Value), nil
+}
+
+func asAnySlice(value any) ([]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case []string:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("expected list, got %T", value)
+	}
+}
+
+func asStringSlice(value any) ([]string, error) {
+	list, err := asAnySlice(value)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string item, got %T", item)
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/result"
+)
+
+// scriptedGraphService answers discovery queries for a tiny graph with one
+// vertex label and one edge label, failing any query containing failOn.
+type scriptedGraphService struct {
+	failOn string
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (s *scriptedGraphService) ExecuteContext(context.Context, string, string) (*result.QueryResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *scriptedGraphService) ExecuteQueryContext(_ context.Context, query, _ string) (*result.QueryResult, error) {
+	s.mu.Lock()
+	s.inFlight++
+	s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+
+	if s.failOn != "" && strings.Contains(query, s.failOn) {
+		return nil, errors.New("boom")
+	}
+	switch {
+	case query == "g.V().label().dedup()":
+		return result.FromPayload(`["Study"]`, result.Options{}), nil
+	case query == "g.E().label().dedup()":
+		return result.FromPayload(`["has_version"]`, result.Options{}), nil
+	case strings.HasPrefix(query, "g.E().project("):
+		return result.FromPayload(`[{"out":"Study","label":"has_version","in":"StudyVersion"}]`, result.Options{}), nil
+	case strings.HasSuffix(query, ".properties().key().dedup()") && strings.HasPrefix(query, "g.V()"):
+		return result.FromPayload(`["phase","name"]`, result.Options{}), nil
+	case strings.HasSuffix(query, ".properties().key().dedup()"):
+		return result.FromPayload(`[]`, result.Options{}), nil
+	case strings.HasSuffix(query, ".count()"):
+		return result.FromPayload(`3`, result.Options{}), nil
+	case strings.Contains(query, ".map(values('phase').fold())"):
+		return result.FromPayload(`[["I"],["II"],[]]`, result.Options{}), nil
+	case strings.Contains(query, ".values('phase')"):
+		return result.FromPayload(`["I","II"]`, result.Options{}), nil
+	}
+	return result.FromPayload(`["a","b","c","d","e","f","g","h","i","j","k"]`, result.Options{}), nil
+}
+
+func TestDiscoverRunsInParallelAndKeepsPartialResults(t *testing.T) {
+	service := &scriptedGraphService{failOn: ".values('name')"}
+	var progressCalls, lastDone, lastTotal int
+
+	schema, err := Discover(context.Background(), service, Options{
+		Concurrency:  2,
+		QueryTimeout: time.Second,
+		SampleSize:   3,
+		Progress: func(done, total int) {
+			if done <= lastDone || total < lastTotal {
+				t.Errorf("progress went from %d/%d to %d/%d", lastDone, lastTotal, done, total)
+			}
+			progressCalls++
+			lastDone, lastTotal = done, total
+		},
+	})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+
+	if service.maxInFlight > 2 {
+		t.Fatalf("expected at most 2 concurrent queries, saw %d", service.maxInFlight)
+	}
+	study := schema.Vertices["Study"]
+	if study.Count != 3 || len(study.Properties) != 2 {
+		t.Fatalf("unexpected Study schema %+v", study)
+	}
+	if phase := study.Properties[1]; phase.Name != "phase" || len(phase.Enum) != 2 {
+		t.Fatalf("expected phase enum, got %+v", phase)
+	} else if phase.Type != TypeString || phase.Sampled != 3 || phase.AlwaysPresent || phase.Cardinality != CardinalityLow {
+		t.Fatalf("expected phase profile, got %+v", phase)
+	}
+	if name := study.Properties[0]; name.Name != "name" || name.Enum != nil {
+		t.Fatalf("expected failed property to be kept without enum, got %+v", name)
+	}
+	if len(schema.Warnings) != 1 || !strings.Contains(schema.Warnings[0], "Study.name") {
+		t.Fatalf("expected one warning for Study.name, got %v", schema.Warnings)
+	}
+	if progressCalls == 0 || lastDone != lastTotal {
+		t.Fatalf("expected progress to finish at total, got %d/%d after %d calls", lastDone, lastTotal, progressCalls)
+	}
+}
+
+func TestDiscoverFailsWithoutLabels(t *testing.T) {
+	service := &scriptedGraphService{failOn: "g.V().label()"}
+	if _, err := Discover(context.Background(), service, Options{Concurrency: 4}); err == nil {
+		t.Fatalf("expected error when vertex labels cannot be listed")
+	}
+}
+
+// presenceGraphService answers discovery queries for Study vertices whose
+// sampled elements all have name and phase, while elements past the sample
+// lack phase.
+type presenceGraphService struct{}
+
+func (presenceGraphService) ExecuteQueryContext(_ context.Context, query, _ string) (*result.QueryResult, error) {
+	switch {
+	case query == "g.V().label().dedup()":
+		return result.FromPayload(`["Study"]`, result.Options{}), nil
+	case strings.HasSuffix(query, ".properties().key().dedup()") && strings.HasPrefix(query, "g.V()"):
+		return result.FromPayload(`["name","phase"]`, result.Options{}), nil
+	case strings.Contains(query, ".not(has('phase'))"):
+		return result.FromPayload(`1`, result.Options{}), nil
+	case strings.HasSuffix(query, ".count()"):
+		return result.FromPayload(`0`, result.Options{}), nil
+	case strings.Contains(query, ".map("):
+		return result.FromPayload(`[["a"],["b"]]`, result.Options{}), nil
+	}
+	return result.FromPayload(`[]`, result.Options{}), nil
+}
+
+func TestDiscoverChecksPresenceAgainstEveryElement(t *testing.T) {
+	schema, err := Discover(context.Background(), presenceGraphService{}, Options{Concurrency: 2, SampleSize: 2})
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	props := schema.Vertices["Study"].Properties
+	if len(props) != 2 || props[0].Name != "name" || props[1].Name != "phase" {
+		t.Fatalf("unexpected Study properties %+v", props)
+	}
+	if name := props[0]; !name.AlwaysPresent {
+		t.Fatalf("expected name to be present on every element, got %+v", name)
+	}
+	if phase := props[1]; phase.AlwaysPresent || phase.MissingRatio != 0 {
+		t.Fatalf("expected phase to be missing past the sample, got %+v", phase)
+	}
+	if len(schema.Warnings) != 0 {
+		t.Fatalf("unexpected warnings %v", schema.Warnings)
+	}
+}
@@ -0,0 +1,477 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Format names an export representation for a schema.
+type Format string
+
+const (
+	JSON       Format = "json"
+	Markdown   Format = "markdown"
+	Mermaid    Format = "mermaid"
+	DOT        Format = "dot"
+	JSONSchema Format = "jsonschema"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// FormatNames lists every export format, in the order shown in help text.
+func FormatNames() []string {
+	return []string{string(JSON), string(Markdown), string(Mermaid), string(DOT), string(JSONSchema)}
+}
+
+// ParseFormat validates a user-supplied export format. An empty name selects
+// JSON.
+func ParseFormat(name string) (Format, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	if normalized == "" {
+		return JSON, nil
+	}
+	if !slices.Contains(FormatNames(), normalized) {
+		return "", fmt.Errorf("invalid schema format %q. Must be one of: %s", name, strings.Join(FormatNames(), ", "))
+	}
+	return Format(normalized), nil
+}
+
+// Write renders g to w in format f.
+func Write(w io.Writer, g *Graph, f Format) error {
+	switch f {
+	case JSON, "":
+		return writeJSON(w, g)
+	case Markdown:
+		return writeMarkdown(w, g)
+	case Mermaid:
+		return writeMermaid(w, g)
+	case DOT:
+		return writeDOT(w, g)
+	case JSONSchema:
+		return writeJSON(w, ToJSONSchema(g))
+	}
+	return fmt.Errorf("unsupported schema format %q", f)
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeMarkdown(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("# Graph schema\n\n")
+	fmt.Fprintf(&b, "Source: %s", g.SchemaVersion)
+	if g.GeneratedAt != "" {
+		fmt.Fprintf(&b, ", generated at %s", g.GeneratedAt)
+	}
+	b.WriteString(".")
+	if g.RootLabel != "" {
+		fmt.Fprintf(&b, " Root vertex label: `%s`.", g.RootLabel)
+	}
+	b.WriteString("\n\n")
+
+	if len(g.Warnings) > 0 {
+		b.WriteString("> **Incomplete:** some discovery queries failed.\n>\n")
+		for _, warning := range g.Warnings {
+			fmt.Fprintf(&b, "> - %s\n", markdownCell(warning))
+		}
+		b.WriteString("\n")
+	}
+
+	counted := hasCounts(g.Vertices)
+	b.WriteString("## Vertex labels\n\n")
+	if counted {
+		b.WriteString("| Label | Count | Properties | Out edges | In edges |\n| --- | ---: | ---: | ---: | ---: |\n")
+	} else {
+		b.WriteString("| Label | Properties | Out edges | In edges |\n| --- | ---: | ---: | ---: |\n")
+	}
+	for _, label := range g.VertexLabels {
+		info := g.Vertices[label]
+		fmt.Fprintf(&b, "| [%s](#%s) |", markdownCell(label), markdownAnchor(label))
+		if counted {
+			fmt.Fprintf(&b, " %d |", info.Count)
+		}
+		fmt.Fprintf(&b, " %d | %d | %d |\n", len(info.Properties), len(g.Outgoing(label)), len(g.Incoming(label)))
+	}
+	b.WriteString("\n")
+
+	withFields := slices.ContainsFunc(g.EdgePatterns, func(p EdgePattern) bool { return p.Field != "" })
+	for _, label := range g.VertexLabels {
+		info := g.Vertices[label]
+		fmt.Fprintf(&b, "### %s\n\n", label)
+		writeMarkdownProperties(&b, info.Properties)
+		writeMarkdownEdges(&b, "Outgoing edges", "To", g.Outgoing(label), withFields, func(p EdgePattern) string { return p.In })
+		writeMarkdownEdges(&b, "Incoming edges", "From", g.Incoming(label), withFields, func(p EdgePattern) string { return p.Out })
+	}
+
+	b.WriteString("## Edge labels\n\n")
+	counted = hasCounts(g.Edges)
+	if counted {
+		b.WriteString("| Edge | Count | Connects |\n| --- | ---: | --- |\n")
+	} else {
+		b.WriteString("| Edge | Connects |\n| --- | --- |\n")
+	}
+	for _, label := range g.EdgeLabels {
+		var connects []string
+		for _, pattern := range g.EdgePatterns {
+			if pattern.Label == label {
+				connects = append(connects, pattern.Out+" → "+pattern.In)
+			}
+		}
+		slices.Sort(connects)
+		connects = slices.Compact(connects)
+		fmt.Fprintf(&b, "| `%s` |", markdownCell(label))
+		if counted {
+			fmt.Fprintf(&b, " %d |", g.Edges[label].Count)
+		}
+		fmt.Fprintf(&b, " %s |\n", markdownCell(strings.Join(connects, ", ")))
+	}
+	for _, label := range g.EdgeLabels {
+		if props := g.Edges[label].Properties; len(props) > 0 {
+			fmt.Fprintf(&b, "\n### Edge %s\n\n", label)
+			writeMarkdownProperties(&b, props)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownProperties(b *strings.Builder, props []Property) {
+	if len(props) == 0 {
+		b.WriteString("No properties.\n\n")
+		return
+	}
+	if slices.ContainsFunc(props, func(prop Property) bool { return prop.Sampled > 0 }) {
+		writeMarkdownProfiles(b, props)
+		return
+	}
+	if !slices.ContainsFunc(props, func(prop Property) bool { return propertyValues(prop) != "" }) {
+		names := make([]string, len(props))
+		for i, prop := range props {
+			names[i] = "`" + markdownCell(prop.Name) + "`"
+		}
+		fmt.Fprintf(b, "Properties: %s.\n\n", strings.Join(names, ", "))
+		return
+	}
+	b.WriteString("| Property | Values |\n| --- | --- |\n")
+	for _, prop := range props {
+		fmt.Fprintf(b, "| `%s` | %s |\n", markdownCell(prop.Name), markdownCell(propertyValues(prop)))
+	}
+	b.WriteString("\n")
+}
+
+// writeMarkdownProfiles renders properties whose values were sampled during
+// discovery, with their inferred type, presence and range.
+func writeMarkdownProfiles(b *strings.Builder, props []Property) {
+	b.WriteString("| Property | Type | Present | Cardinality | Range | Values |\n| --- | --- | ---: | --- | --- | --- |\n")
+	for _, prop := range props {
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			markdownCell(prop.Name),
+			markdownCell(propertyType(prop)),
+			propertyPresence(prop),
+			prop.Cardinality,
+			markdownCell(propertyRange(prop)),
+			markdownCell(propertyValues(prop)),
+		)
+	}
+	b.WriteString("\n")
+}
+
+// propertyType names the inferred type of prop, with the breakdown of mixed
+// types.
+func propertyType(prop Property) string {
+	if prop.Type != TypeMixed {
+		return prop.Type
+	}
+	parts := make([]string, 0, len(prop.Types))
+	for _, kind := range sortedKeys(prop.Types) {
+		parts = append(parts, fmt.Sprintf("%s %d", kind, prop.Types[kind]))
+	}
+	return fmt.Sprintf("mixed (%s)", strings.Join(parts, ", "))
+}
+
+func propertyPresence(prop Property) string {
+	switch {
+	case prop.Sampled == 0:
+		return ""
+	case prop.AlwaysPresent:
+		return "always"
+	case prop.MissingRatio == 0:
+		// Every sampled element had it, but not every element does.
+		return "<100%"
+	}
+	return fmt.Sprintf("%.0f%%", (1-prop.MissingRatio)*100)
+}
+
+func propertyRange(prop Property) string {
+	if prop.Min == nil || prop.Max == nil {
+		return ""
+	}
+	return joinValues([]any{prop.Min}) + " – " + joinValues([]any{prop.Max})
+}
+
+// propertyValues summarises the known values of prop: its enum when the
+// property has few distinct values, otherwise a few samples.
+func propertyValues(prop Property) string {
+	switch {
+	case len(prop.Enum) > 0:
+		return "one of " + joinValues(prop.Enum)
+	case len(prop.SampleValues) > 0:
+		return "e.g. " + joinValues(prop.SampleValues)
+	}
+	return ""
+}
+
+func joinValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		parts[i] = string(encoded)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// writeMarkdownEdges lists patterns with a link to the vertex label at their
+// other end. The Field column is only shown when the schema records fields.
+func writeMarkdownEdges(b *strings.Builder, title, endHeader string, patterns []EdgePattern, withFields bool, end func(EdgePattern) string) {
+	if len(patterns) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n\n", title)
+	if withFields {
+		fmt.Fprintf(b, "| Edge | Field | %s |\n| --- | --- | --- |\n", endHeader)
+	} else {
+		fmt.Fprintf(b, "| Edge | %s |\n| --- | --- |\n", endHeader)
+	}
+	for _, pattern := range patterns {
+		fmt.Fprintf(b, "| `%s` |", markdownCell(pattern.Label))
+		if withFields {
+			fmt.Fprintf(b, " `%s` |", markdownCell(pattern.Field))
+		}
+		fmt.Fprintf(b, " [%s](#%s) |\n", markdownCell(end(pattern)), markdownAnchor(end(pattern)))
+	}
+	b.WriteString("\n")
+}
+
+func hasCounts(labels map[string]Label) bool {
+	for _, info := range labels {
+		if info.Count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// markdownAnchor matches the heading anchors generated by GitHub and most
+// wikis: lower case, punctuation removed and spaces turned into hyphens.
+func markdownAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// writeMermaid renders an ER diagram. Attributes are typed with the inferred
+// property type, or "any" when it is not known.
+func writeMermaid(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, label := range g.VertexLabels {
+		props := g.Vertices[label].Properties
+		if len(props) == 0 {
+			fmt.Fprintf(&b, "    %s\n", mermaidName(label))
+			continue
+		}
+		fmt.Fprintf(&b, "    %s {\n", mermaidName(label))
+		for _, prop := range props {
+			kind := prop.Type
+			if kind == "" {
+				kind = "any"
+			}
+			fmt.Fprintf(&b, "        %s %s\n", kind, mermaidName(prop.Name))
+		}
+		b.WriteString("    }\n")
+	}
+	for _, pattern := range g.EdgePatterns {
+		fmt.Fprintf(&b, "    %s ||--o{ %s : \"%s\"\n", mermaidName(pattern.Out), mermaidName(pattern.In), strings.ReplaceAll(pattern.Label, `"`, "'"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidName(name string) string {
+	return mermaidUnsafe.ReplaceAllString(name, "_")
+}
+
+func writeDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph schema {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=record, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, label := range g.VertexLabels {
+		var fields strings.Builder
+		for _, prop := range g.Vertices[label].Properties {
+			fields.WriteString(dotRecordText(prop.Name))
+			fields.WriteString(`\l`)
+		}
+		record := dotRecordText(label)
+		if fields.Len() > 0 {
+			record = "{" + record + "|" + fields.String() + "}"
+		}
+		fmt.Fprintf(&b, "  %s [label=\"%s\"];\n", dotID(label), record)
+	}
+	for _, pattern := range g.EdgePatterns {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotID(pattern.Out), dotID(pattern.In), dotID(pattern.Label))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotID(name string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
+
+// dotRecordText escapes text for use inside a quoted record label, where
+// braces, bars and angle brackets are structural.
+func dotRecordText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, `"`, `\"`,
+		`{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`,
+		"\n", " ",
+	).Replace(text)
+}
+
+// ToJSONSchema describes each vertex label as an object definition. Outgoing
+// edges become properties holding one or more child objects, named after the
+// document field when known and after the edge label otherwise. When the
+// root label is known, the document itself is that definition.
+func ToJSONSchema(g *Graph) *jsonschema.Schema {
+	doc := &jsonschema.Schema{
+		Schema:      jsonSchemaDialect,
+		Title:       "Graph schema",
+		Description: fmt.Sprintf("Generated from the %s graph schema.", g.SchemaVersion),
+		Defs:        make(map[string]*jsonschema.Schema, len(g.VertexLabels)),
+	}
+	if g.RootLabel != "" {
+		doc.Ref = "#/$defs/" + g.RootLabel
+	}
+
+	for _, label := range g.VertexLabels {
+		def := &jsonschema.Schema{
+			Type:       "object",
+			Title:      label,
+			Properties: make(map[string]*jsonschema.Schema),
+		}
+		for _, prop := range g.Vertices[label].Properties {
+			def.Properties[prop.Name] = propertyJSONSchema(prop)
+		}
+		for _, pattern := range g.Outgoing(label) {
+			key := pattern.Field
+			if key == "" {
+				key = pattern.Label
+			}
+			ref := "#/$defs/" + pattern.In
+			child := &jsonschema.Schema{
+				Description: fmt.Sprintf("Edge %s to %s.", pattern.Label, pattern.In),
+				AnyOf: []*jsonschema.Schema{
+					{Ref: ref},
+					{Type: "array", Items: &jsonschema.Schema{Ref: ref}},
+				},
+			}
+			if existing, ok := def.Properties[key]; ok && len(existing.AnyOf) > 0 {
+				// The same edge label reaches several child labels.
+				existing.AnyOf = append(existing.AnyOf, child.AnyOf...)
+				existing.Description = fmt.Sprintf("Edge %s.", pattern.Label)
+				continue
+			}
+			def.Properties[key] = child
+		}
+		doc.Defs[label] = def
+	}
+	return doc
+}
+
+// jsonSchemaTypes maps inferred property types to JSON Schema types. Dates
+// and embedded JSON are stored as strings.
+var jsonSchemaTypes = map[string]string{
+	TypeString:  "string",
+	TypeNumber:  "number",
+	TypeBoolean: "boolean",
+	TypeDate:    "string",
+	TypeJSON:    "string",
+	TypeList:    "array",
+	TypeObject:  "object",
+}
+
+// propertyJSONSchema describes one property. Sampled statistics only go into
+// the description: a sample cannot prove a range or that a property is
+// required.
+func propertyJSONSchema(prop Property) *jsonschema.Schema {
+	out := &jsonschema.Schema{
+		Enum:     prop.Enum,
+		Examples: prop.SampleValues,
+	}
+	switch prop.Type {
+	case "":
+	case TypeMixed:
+		var types []string
+		for _, kind := range sortedKeys(prop.Types) {
+			types = append(types, jsonSchemaTypes[kind])
+		}
+		slices.Sort(types)
+		if types = slices.Compact(types); len(types) == 1 {
+			out.Type = types[0]
+		} else {
+			out.Types = types
+		}
+	default:
+		out.Type = jsonSchemaTypes[prop.Type]
+	}
+	if prop.Type == TypeJSON {
+		out.ContentMediaType = "application/json"
+	}
+
+	var notes []string
+	if prop.Type == TypeDate {
+		notes = append(notes, "Date or timestamp.")
+	}
+	if prop.Sampled > 0 {
+		presence := propertyPresence(prop)
+		if presence == "always" {
+			presence = "all"
+		}
+		notes = append(notes, fmt.Sprintf("Present on %s of %d sampled elements.", presence, prop.Sampled))
+	}
+	if valueRange := propertyRange(prop); valueRange != "" {
+		notes = append(notes, "Sampled range "+valueRange+".")
+	}
+	out.Description = strings.Join(notes, " ")
+	return out
+}
@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	return &Graph{
+		SchemaVersion: string(Dynamic),
+		RootLabel:     "Study",
+		VertexLabels:  []string{"Study", "Study Version"},
+		EdgeLabels:    []string{"has_version"},
+		EdgePatterns:  []EdgePattern{{Out: "Study", Label: "has_version", In: "Study Version"}},
+		Vertices: map[string]Label{
+			"Study": {Count: 2, Properties: []Property{
+				{Name: "name", SampleValues: []any{"ABC|1"}},
+				{Name: "phase", Enum: []any{"I", "II"}},
+			}},
+			"Study Version": {Count: 3},
+		},
+		Edges: map[string]Label{"has_version": {Count: 3}},
+	}
+}
+
+func TestStaticGraphIncludesChildOnlyLabels(t *testing.T) {
+	doc, err := ParseStatic()
+	if err != nil {
+		t.Fatalf("ParseStatic: %v", err)
+	}
+	g := doc.Graph()
+
+	if !slices.Contains(g.VertexLabels, "ExtensionAttribute") {
+		t.Fatalf("expected labels only reached by edges to be listed, got %v", g.VertexLabels)
+	}
+	if g.RootLabel != "Study" || !slices.Contains(g.EdgeLabels, "has_version") {
+		t.Fatalf("unexpected graph header %q %v", g.RootLabel, g.EdgeLabels)
+	}
+	out := g.Outgoing("Study")
+	if len(out) != 1 || out[0] != (EdgePattern{Out: "Study", Label: "has_version", In: "StudyVersion", Field: "versions"}) {
+		t.Fatalf("unexpected Study edges %+v", out)
+	}
+	if props := g.Vertices["StudyTitle"].Properties; props[0].Name != "id" || props[len(props)-1].Name != "text" {
+		t.Fatalf("expected shared then label properties, got %+v", props)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testGraph(), Markdown); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"| [Study Version](#study-version) | 3 | 0 | 0 | 1 |",
+		"| `name` | e.g. \"ABC\\|1\" |",
+		"| `phase` | one of \"I\", \"II\" |",
+		"| `has_version` | [Study Version](#study-version) |",
+		"| `has_version` | 3 | Study → Study Version |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in markdown:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteDiagrams(t *testing.T) {
+	var mermaid, dot bytes.Buffer
+	if err := Write(&mermaid, testGraph(), Mermaid); err != nil {
+		t.Fatalf("Write mermaid: %v", err)
+	}
+	if err := Write(&dot, testGraph(), DOT); err != nil {
+		t.Fatalf("Write dot: %v", err)
+	}
+
+	if !strings.Contains(mermaid.String(), `Study ||--o{ Study_Version : "has_version"`) {
+		t.Fatalf("unexpected mermaid output:\n%s", mermaid.String())
+	}
+	if !strings.Contains(mermaid.String(), "        any phase\n") {
+		t.Fatalf("expected attributes in mermaid output:\n%s", mermaid.String())
+	}
+	if !strings.Contains(dot.String(), `"Study" -> "Study Version" [label="has_version"];`) {
+		t.Fatalf("unexpected dot output:\n%s", dot.String())
+	}
+	if !strings.Contains(dot.String(), `"Study" [label="{Study|name\lphase\l}"];`) {
+		t.Fatalf("expected record node in dot output:\n%s", dot.String())
+	}
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testGraph(), JSONSchema); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var doc struct {
+		Ref  string `json:"$ref"`
+		Defs map[string]struct {
+			Properties map[string]struct {
+				Enum  []any `json:"enum"`
+				AnyOf []struct {
+					Ref string `json:"$ref"`
+				} `json:"anyOf"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON Schema: %v\n%s", err, buf.String())
+	}
+	if doc.Ref != "#/$defs/Study" {
+		t.Fatalf("expected root ref, got %q", doc.Ref)
+	}
+	study := doc.Defs["Study"]
+	if len(study.Properties["phase"].Enum) != 2 {
+		t.Fatalf("expected phase enum, got %+v", study.Properties["phase"])
+	}
+	if edge := study.Properties["has_version"]; len(edge.AnyOf) != 2 || edge.AnyOf[0].Ref != "#/$defs/Study Version" {
+		t.Fatalf("expected edge property referencing the child, got %+v", edge)
+	}
+}
+
+func TestWriteProfiledProperties(t *testing.T) {
+	g := testGraph()
+	g.Vertices["Study"] = Label{Count: 2, Properties: []Property{
+		{Name: "enrollment", Type: TypeNumber, Sampled: 4, MissingRatio: 0.25, Min: 10.0, Max: 250.0, Distinct: 3, Cardinality: CardinalityUnique},
+		{Name: "meta", Type: TypeMixed, Types: map[string]int{TypeJSON: 2, TypeString: 1}, Sampled: 3, AlwaysPresent: true},
+	}}
+
+	var markdown, mermaid, jsonSchema bytes.Buffer
+	for out, f := range map[*bytes.Buffer]Format{&markdown: Markdown, &mermaid: Mermaid, &jsonSchema: JSONSchema} {
+		if err := Write(out, g, f); err != nil {
+			t.Fatalf("Write %s: %v", f, err)
+		}
+	}
+
+	if !strings.Contains(markdown.String(), "| `enrollment` | number | 75% | unique | 10 – 250 |  |") ||
+		!strings.Contains(markdown.String(), "| `meta` | mixed (json 2, string 1) | always |") {
+		t.Fatalf("expected profile table in markdown:\n%s", markdown.String())
+	}
+	if !strings.Contains(mermaid.String(), "        number enrollment\n") {
+		t.Fatalf("expected inferred attribute types in mermaid output:\n%s", mermaid.String())
+	}
+
+	var doc struct {
+		Defs map[string]struct {
+			Properties map[string]struct {
+				Type        string `json:"type"`
+				Description string `json:"description"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(jsonSchema.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON Schema: %v", err)
+	}
+	enrollment := doc.Defs["Study"].Properties["enrollment"]
+	if enrollment.Type != "number" || enrollment.Description != "Present on 75% of 4 sampled elements. Sampled range 10 – 250." {
+		t.Fatalf("unexpected enrollment schema %+v", enrollment)
+	}
+	if meta := doc.Defs["Study"].Properties["meta"]; meta.Type != "string" {
+		t.Fatalf("expected json and string to collapse to one type, got %+v", meta)
+	}
+}
+
+func TestParseFormatAndSource(t *testing.T) {
+	if f, err := ParseFormat(" Mermaid "); err != nil || f != Mermaid {
+		t.Fatalf("ParseFormat: %q, %v", f, err)
+	}
+	if _, err := ParseFormat("svg"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+	if src, err := ParseSource(""); err != nil || src != Static {
+		t.Fatalf("ParseSource: %q, %v", src, err)
+	}
+	if _, err := ParseSource("live"); err == nil {
+		t.Fatalf("expected error for unknown source")
+	}
+}
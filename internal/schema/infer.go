@@ -0,0 +1,228 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ankit-lilly/nqcli/internal/params"
+)
+
+// Inferred property types.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	// TypeDate is a string holding a date or timestamp.
+	TypeDate = "date"
+	// TypeJSON is a string holding a JSON object or array.
+	TypeJSON = "json"
+	// TypeList is a property with several values on one element.
+	TypeList   = "list"
+	TypeObject = "object"
+	// TypeMixed is reported when sampled values disagree; Property.Types
+	// then holds the breakdown.
+	TypeMixed = "mixed"
+)
+
+// Cardinality classes, from the number of distinct sampled values.
+const (
+	CardinalityConstant = "constant"
+	CardinalityLow      = "low"
+	CardinalityHigh     = "high"
+	CardinalityUnique   = "unique"
+)
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// queryPropertySample returns the values of prop on up to size elements of
+// label, one list per element so that missing and multi-valued properties
+// can be told apart.
+func queryPropertySample(ctx context.Context, exec Executor, source, label, prop string, size int) ([]any, error) {
+	query := fmt.Sprintf(
+		"%s.hasLabel(%s).limit(%d).map(values(%s).fold())",
+		source,
+		params.QuoteGremlin(label),
+		size,
+		params.QuoteGremlin(prop),
+	)
+	return queryAnyList(ctx, exec, query)
+}
+
+// queryAlwaysPresent reports whether every element of label has prop.
+func queryAlwaysPresent(ctx context.Context, exec Executor, source, label, prop string) (bool, error) {
+	query := fmt.Sprintf(
+		"%s.hasLabel(%s).not(has(%s)).limit(1).count()",
+		source,
+		params.QuoteGremlin(label),
+		params.QuoteGremlin(prop),
+	)
+	missing, err := queryCount(ctx, exec, query)
+	return missing == 0, err
+}
+
+// profileProperty fills in the statistics of prop from samples, the result
+// of queryPropertySample. An element counts as missing the property when it
+// has no value or only nulls.
+func profileProperty(prop *Property, samples []any) {
+	prop.Sampled = len(samples)
+	if prop.Sampled == 0 {
+		return
+	}
+
+	var (
+		missing, present int
+		types            = make(map[string]int)
+		distinct         = make(map[string]bool)
+		numbers          []float64
+		dates            []string
+	)
+	for _, sample := range samples {
+		values := nonNull(sample)
+		if len(values) == 0 {
+			missing++
+			continue
+		}
+		present++
+		if len(values) > 1 {
+			types[TypeList]++
+			distinct[canonicalValue(values)] = true
+			continue
+		}
+
+		value := values[0]
+		kind := inferType(value)
+		types[kind]++
+		distinct[canonicalValue(value)] = true
+		switch kind {
+		case TypeNumber:
+			if number, ok := toFloat(value); ok {
+				numbers = append(numbers, number)
+			}
+		case TypeDate:
+			dates = append(dates, value.(string))
+		}
+	}
+
+	prop.MissingRatio = math.Round(float64(missing)/float64(prop.Sampled)*1000) / 1000
+	prop.AlwaysPresent = missing == 0
+	if present == 0 {
+		return
+	}
+
+	if len(types) == 1 {
+		for kind := range types {
+			prop.Type = kind
+		}
+	} else {
+		prop.Type = TypeMixed
+		prop.Types = types
+	}
+	switch prop.Type {
+	case TypeNumber:
+		if len(numbers) > 0 {
+			prop.Min, prop.Max = slices.Min(numbers), slices.Max(numbers)
+		}
+	case TypeDate:
+		byTime := func(a, b string) int {
+			ta, _ := parseDate(a)
+			tb, _ := parseDate(b)
+			return ta.Compare(tb)
+		}
+		prop.Min, prop.Max = slices.MinFunc(dates, byTime), slices.MaxFunc(dates, byTime)
+	}
+
+	prop.Distinct = len(distinct)
+	if len(prop.Enum) > 0 {
+		prop.Distinct = len(prop.Enum)
+	}
+	switch {
+	case prop.Distinct == 1:
+		prop.Cardinality = CardinalityConstant
+	case len(prop.Enum) > 0:
+		prop.Cardinality = CardinalityLow
+	case prop.Distinct == present:
+		prop.Cardinality = CardinalityUnique
+	default:
+		prop.Cardinality = CardinalityHigh
+	}
+}
+
+// inferType classifies a single property value.
+func inferType(value any) string {
+	switch v := value.(type) {
+	case bool:
+		return TypeBoolean
+	case float64, json.Number:
+		return TypeNumber
+	case []any:
+		return TypeList
+	case map[string]any:
+		return TypeObject
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			if json.Valid([]byte(trimmed)) {
+				return TypeJSON
+			}
+		}
+		if _, ok := parseDate(v); ok {
+			return TypeDate
+		}
+	}
+	return TypeString
+}
+
+func parseDate(text string) (time.Time, bool) {
+	text = strings.TrimSpace(text)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		parsed, err := v.Float64()
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+// nonNull returns the values of one sampled element without nulls. Elements
+// that are not lists hold a single value.
+func nonNull(sample any) []any {
+	values, ok := sample.([]any)
+	if !ok {
+		values = []any{sample}
+	}
+	out := values[:0:0]
+	for _, value := range values {
+		if value != nil {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func canonicalValue(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInferType(t *testing.T) {
+	for _, tc := range []struct {
+		value any
+		want  string
+	}{
+		{"Phase I", TypeString},
+		{"2024-03-01", TypeDate},
+		{"2024-03-01T10:00:00Z", TypeDate},
+		{`{"a": 1}`, TypeJSON},
+		{"[1, 2]", TypeJSON},
+		{"[draft]", TypeString},
+		{3.5, TypeNumber},
+		{json.Number("4"), TypeNumber},
+		{true, TypeBoolean},
+		{map[string]any{"a": 1.0}, TypeObject},
+	} {
+		if got := inferType(tc.value); got != tc.want {
+			t.Fatalf("inferType(%#v) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestProfilePropertyNumbers(t *testing.T) {
+	var samples []any
+	if err := json.Unmarshal([]byte(`[[3], [1.5], [null], [], [12]]`), &samples); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	prop := Property{Name: "enrollment"}
+	profileProperty(&prop, samples)
+
+	if prop.Type != TypeNumber || prop.Sampled != 5 || prop.MissingRatio != 0.4 || prop.AlwaysPresent {
+		t.Fatalf("unexpected profile %+v", prop)
+	}
+	if prop.Min != 1.5 || prop.Max != 12.0 {
+		t.Fatalf("expected range 1.5..12, got %v..%v", prop.Min, prop.Max)
+	}
+	if prop.Distinct != 3 || prop.Cardinality != CardinalityUnique {
+		t.Fatalf("expected unique cardinality, got %d %q", prop.Distinct, prop.Cardinality)
+	}
+}
+
+func TestProfilePropertyMixedListsAndDates(t *testing.T) {
+	var samples []any
+	if err := json.Unmarshal([]byte(`[["2024-05-01"], ["2023-01-15T08:00:00Z"], ["a", "b"], ["2024-05-01"]]`), &samples); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	prop := Property{Name: "date"}
+	profileProperty(&prop, samples)
+
+	if prop.Type != TypeMixed || prop.Types[TypeDate] != 3 || prop.Types[TypeList] != 1 {
+		t.Fatalf("expected mixed date and list, got %+v", prop)
+	}
+	if !prop.AlwaysPresent || prop.Cardinality != CardinalityHigh || prop.Min != nil {
+		t.Fatalf("unexpected profile %+v", prop)
+	}
+
+	dates := Property{Name: "date"}
+	profileProperty(&dates, samples[:2])
+	if dates.Type != TypeDate || dates.Min != "2023-01-15T08:00:00Z" || dates.Max != "2024-05-01" {
+		t.Fatalf("expected date range, got %+v", dates)
+	}
+
+	constant := Property{Name: "status", Enum: []any{"active"}}
+	profileProperty(&constant, []any{[]any{"active"}, []any{"active"}})
+	if constant.Cardinality != CardinalityConstant || constant.Distinct != 1 {
+		t.Fatalf("expected constant, got %+v", constant)
+	}
+}
@@ -0,0 +1,135 @@
+// Package schema describes the shape of the Neptune graph.
+//
+// Two sources are supported: the static schema embedded in the binary, which
+// is maintained by hand alongside the data model, and dynamic discovery, which
+// queries a live graph for its labels, properties, edge patterns, counts,
+// low-cardinality values and sampled property profiles. Both can be converted
+// to a Graph and exported as Markdown, Mermaid, Graphviz DOT or JSON Schema.
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Source selects where a schema comes from.
+type Source string
+
+const (
+	// Static is the schema embedded in the binary.
+	Static Source = "static"
+	// Dynamic is the schema discovered from the live graph.
+	Dynamic Source = "dynamic"
+)
+
+// SourceNames lists every supported source, in the order shown in help text.
+func SourceNames() []string {
+	return []string{string(Static), string(Dynamic)}
+}
+
+// ParseSource validates a user-supplied source name. An empty name selects
+// the static schema.
+func ParseSource(name string) (Source, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	if normalized == "" {
+		return Static, nil
+	}
+	if !slices.Contains(SourceNames(), normalized) {
+		return "", fmt.Errorf("invalid schema source %q. Must be one of: %s", name, strings.Join(SourceNames(), ", "))
+	}
+	return Source(normalized), nil
+}
+
+// Graph is the common representation of a schema, whichever its source.
+type Graph struct {
+	SchemaVersion string `json:"schema_version"`
+	GeneratedAt   string `json:"generated_at"`
+	// RootLabel is the vertex label documents hang from, when known.
+	RootLabel    string           `json:"root_label,omitempty"`
+	VertexLabels []string         `json:"vertex_labels"`
+	EdgeLabels   []string         `json:"edge_labels"`
+	EdgePatterns []EdgePattern    `json:"edge_patterns"`
+	Vertices     map[string]Label `json:"vertices"`
+	Edges        map[string]Label `json:"edges"`
+	// Warnings lists discovery steps that failed; the affected labels or
+	// properties are incomplete.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// EdgePattern is an edge label together with the vertex labels it connects.
+type EdgePattern struct {
+	Out   string `json:"out"`
+	Label string `json:"label"`
+	In    string `json:"in"`
+	// Field is the document field the edge was created from. Only the static
+	// schema records it.
+	Field string `json:"field,omitempty"`
+}
+
+// Label describes the elements carrying one vertex or edge label.
+type Label struct {
+	Count      int64      `json:"count,omitempty"`
+	Properties []Property `json:"properties,omitempty"`
+}
+
+// Property describes one property key of a label. Apart from the name, the
+// fields are only known for discovered schemas; except for AlwaysPresent, the
+// statistics describe the first Sampled elements of the label rather than all
+// of them.
+type Property struct {
+	Name         string `json:"name"`
+	Enum         []any  `json:"enum,omitempty"`
+	SampleValues []any  `json:"sample_values,omitempty"`
+
+	// Type is one of the Type constants, TypeMixed when sampled values
+	// disagree.
+	Type string `json:"type,omitempty"`
+	// Types counts sampled values per type when Type is TypeMixed.
+	Types   map[string]int `json:"types,omitempty"`
+	Sampled int            `json:"sampled,omitempty"`
+	// MissingRatio is the share of sampled elements without a non-null
+	// value, rounded to three decimals.
+	MissingRatio float64 `json:"missing_ratio,omitempty"`
+	// AlwaysPresent is checked against every element of the label, so it
+	// can be false while MissingRatio is 0.
+	AlwaysPresent bool `json:"always_present,omitempty"`
+	// Min and Max bound numeric values and date strings.
+	Min any `json:"min,omitempty"`
+	Max any `json:"max,omitempty"`
+	// Distinct counts distinct values: exact when Enum is set, within the
+	// sample otherwise. Cardinality is one of the Cardinality constants.
+	Distinct    int    `json:"distinct,omitempty"`
+	Cardinality string `json:"cardinality,omitempty"`
+}
+
+// Outgoing returns the edge patterns leaving label, in pattern order.
+func (g *Graph) Outgoing(label string) []EdgePattern {
+	var out []EdgePattern
+	for _, pattern := range g.EdgePatterns {
+		if pattern.Out == label {
+			out = append(out, pattern)
+		}
+	}
+	return out
+}
+
+// Incoming returns the edge patterns entering label, in pattern order.
+func (g *Graph) Incoming(label string) []EdgePattern {
+	var in []EdgePattern
+	for _, pattern := range g.EdgePatterns {
+		if pattern.In == label {
+			in = append(in, pattern)
+		}
+	}
+	return in
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,281 @@
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+const sharedPropertiesKey = "all_vertices"
+
+//go:embed static.json
+var staticJSON string
+
+// StaticJSON returns the embedded schema document.
+func StaticJSON() string {
+	return strings.TrimSpace(staticJSON)
+}
+
+// StaticDoc is a static schema document, either the embedded one or one
+// loaded with LoadStatic.
+type StaticDoc struct {
+	SchemaVersion      string                            `json:"schema_version"`
+	RootLabel          string                            `json:"root_label"`
+	Notes              []string                          `json:"notes"`
+	Properties         map[string][]string               `json:"properties"`
+	KnownInstanceTypes map[string][]string               `json:"known_instance_types"`
+	Schema             map[string]map[string]StaticChild `json:"schema"`
+
+	// Path is the file the document was loaded from; empty for the embedded
+	// document.
+	Path string `json:"-"`
+	raw  string
+}
+
+// StaticChild is a document field that is stored as an edge to a child
+// vertex.
+type StaticChild struct {
+	EdgeLabel  string `json:"edgeLabel"`
+	ChildLabel string `json:"childLabel"`
+}
+
+// StaticLabel describes one vertex label: its properties and the edges that
+// leave and enter it.
+type StaticLabel struct {
+	Label         string       `json:"label"`
+	Properties    []string     `json:"properties"`
+	InstanceTypes []string     `json:"instance_types,omitempty"`
+	Outgoing      []StaticEdge `json:"outgoing,omitempty"`
+	Incoming      []StaticEdge `json:"incoming,omitempty"`
+}
+
+// StaticEdge is an edge between two vertex labels in the static schema.
+type StaticEdge struct {
+	Field     string `json:"field"`
+	EdgeLabel string `json:"edgeLabel"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// ValidationError reports why a static schema document was rejected.
+type ValidationError struct {
+	// Source is the file path, or "embedded" for the built-in document.
+	Source   string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid static schema %s:", e.Source)
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&b, "\n  - %s", problem)
+	}
+	return b.String()
+}
+
+// ParseStatic parses the embedded schema document.
+func ParseStatic() (*StaticDoc, error) {
+	return parseStatic("embedded", []byte(staticJSON))
+}
+
+// LoadStatic reads and validates the schema document at path, or returns the
+// embedded document when path is empty. A document that fails validation is
+// rejected with a *ValidationError rather than replaced by the embedded one.
+func LoadStatic(path string) (*StaticDoc, error) {
+	if path == "" {
+		return ParseStatic()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read static schema: %w", err)
+	}
+	doc, err := parseStatic(path, data)
+	if err != nil {
+		return nil, err
+	}
+	doc.Path = path
+	return doc, nil
+}
+
+func parseStatic(source string, data []byte) (*StaticDoc, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var doc StaticDoc
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, &ValidationError{Source: source, Problems: []string{describeJSONError(data, err)}}
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return nil, &ValidationError{Source: source, Problems: []string{"unexpected data after the schema document"}}
+	}
+	if problems := doc.validate(); len(problems) > 0 {
+		return nil, &ValidationError{Source: source, Problems: problems}
+	}
+	doc.raw = strings.TrimSpace(string(data))
+	return &doc, nil
+}
+
+// JSON returns the document as it was read.
+func (d *StaticDoc) JSON() string {
+	return d.raw
+}
+
+// validate checks the references inside the document: the root label and
+// property lists must name labels in the schema, and every child needs an
+// edge label and a child label.
+func (d *StaticDoc) validate() []string {
+	var problems []string
+	if len(d.Schema) == 0 {
+		problems = append(problems, "schema: at least one vertex label is required")
+	}
+
+	known := make(map[string]bool)
+	for _, parent := range sortedKeys(d.Schema) {
+		known[parent] = true
+		for _, field := range sortedKeys(d.Schema[parent]) {
+			child := d.Schema[parent][field]
+			if strings.TrimSpace(child.EdgeLabel) == "" {
+				problems = append(problems, fmt.Sprintf("schema.%s.%s: edgeLabel is required", parent, field))
+			}
+			if strings.TrimSpace(child.ChildLabel) == "" {
+				problems = append(problems, fmt.Sprintf("schema.%s.%s: childLabel is required", parent, field))
+				continue
+			}
+			known[child.ChildLabel] = true
+		}
+	}
+
+	if _, ok := d.Schema[d.RootLabel]; d.RootLabel == "" {
+		problems = append(problems, "root_label: is required")
+	} else if !ok && len(d.Schema) > 0 {
+		problems = append(problems, fmt.Sprintf("root_label: %q is not a vertex label in schema", d.RootLabel))
+	}
+
+	for _, label := range sortedKeys(d.Properties) {
+		if label != sharedPropertiesKey && !known[label] {
+			problems = append(problems, fmt.Sprintf("properties.%s: %q is not a vertex label in schema", label, label))
+			continue
+		}
+		seen := make(map[string]bool)
+		if label != sharedPropertiesKey {
+			for _, name := range d.Properties[sharedPropertiesKey] {
+				seen[name] = true
+			}
+		}
+		for i, name := range d.Properties[label] {
+			switch {
+			case strings.TrimSpace(name) == "":
+				problems = append(problems, fmt.Sprintf("properties.%s[%d]: property name is empty", label, i))
+			case seen[name]:
+				problems = append(problems, fmt.Sprintf("properties.%s[%d]: duplicate property %q", label, i, name))
+			}
+			seen[name] = true
+		}
+	}
+
+	for _, label := range sortedKeys(d.KnownInstanceTypes) {
+		if !known[label] {
+			problems = append(problems, fmt.Sprintf("known_instance_types.%s: %q is not a vertex label in schema", label, label))
+		}
+	}
+	return problems
+}
+
+// describeJSONError turns a decoding error into a message with the line and
+// column of the offending input.
+func describeJSONError(data []byte, err error) string {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("%s: %v", position(data, syntaxErr.Offset), syntaxErr)
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("%s: %s must be %s, got %s", position(data, typeErr.Offset), typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.Is(err, io.EOF):
+		return "document is empty"
+	}
+	return strings.TrimPrefix(err.Error(), "json: ")
+}
+
+// position locates the byte just before offset, which is where the decoder
+// stopped reading.
+func position(data []byte, offset int64) string {
+	before := data[:min(max(offset-1, 0), int64(len(data)))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// PropertiesOf returns the property keys of label, including those shared by
+// all vertices.
+func (d *StaticDoc) PropertiesOf(label string) []string {
+	return append(slices.Clone(d.Properties[sharedPropertiesKey]), d.Properties[label]...)
+}
+
+// Label returns the static schema for label, or false if the label is not
+// part of it.
+func (d *StaticDoc) Label(label string) (*StaticLabel, bool) {
+	children, known := d.Schema[label]
+	if !known {
+		return nil, false
+	}
+
+	out := &StaticLabel{
+		Label:         label,
+		Properties:    d.PropertiesOf(label),
+		InstanceTypes: d.KnownInstanceTypes[label],
+	}
+	for _, field := range sortedKeys(children) {
+		child := children[field]
+		out.Outgoing = append(out.Outgoing, StaticEdge{Field: field, EdgeLabel: child.EdgeLabel, From: label, To: child.ChildLabel})
+	}
+	for _, parent := range sortedKeys(d.Schema) {
+		for _, field := range sortedKeys(d.Schema[parent]) {
+			if child := d.Schema[parent][field]; child.ChildLabel == label {
+				out.Incoming = append(out.Incoming, StaticEdge{Field: field, EdgeLabel: child.EdgeLabel, From: parent, To: label})
+			}
+		}
+	}
+	return out, true
+}
+
+// Graph converts the document to the common representation. Labels that only
+// appear as edge targets are included as vertex labels.
+func (d *StaticDoc) Graph() *Graph {
+	g := &Graph{
+		SchemaVersion: string(Static),
+		RootLabel:     d.RootLabel,
+		Vertices:      make(map[string]Label),
+		Edges:         make(map[string]Label),
+	}
+
+	vertexLabels := make(map[string]bool)
+	for _, parent := range sortedKeys(d.Schema) {
+		vertexLabels[parent] = true
+		for _, field := range sortedKeys(d.Schema[parent]) {
+			child := d.Schema[parent][field]
+			vertexLabels[child.ChildLabel] = true
+			g.Edges[child.EdgeLabel] = Label{}
+			g.EdgePatterns = append(g.EdgePatterns, EdgePattern{Out: parent, Label: child.EdgeLabel, In: child.ChildLabel, Field: field})
+		}
+	}
+
+	g.VertexLabels = sortedKeys(vertexLabels)
+	g.EdgeLabels = sortedKeys(g.Edges)
+	for _, label := range g.VertexLabels {
+		var props []Property
+		for _, name := range d.PropertiesOf(label) {
+			props = append(props, Property{Name: name})
+		}
+		g.Vertices[label] = Label{Properties: props}
+	}
+	return g
+}
@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeSchemaFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	return path
+}
+
+func TestLoadStaticFallsBackToEmbedded(t *testing.T) {
+	doc, err := LoadStatic("")
+	if err != nil {
+		t.Fatalf("LoadStatic: %v", err)
+	}
+	if doc.Path != "" || doc.JSON() != StaticJSON() || doc.RootLabel != "Study" {
+		t.Fatalf("expected the embedded schema, got path=%q root=%q", doc.Path, doc.RootLabel)
+	}
+}
+
+func TestLoadStaticReadsFile(t *testing.T) {
+	content := `{
+  "schema_version": "static",
+  "root_label": "Trial",
+  "notes": ["Trials only."],
+  "properties": {"all_vertices": ["id"], "Site": ["city"]},
+  "schema": {"Trial": {"sites": {"edgeLabel": "has_site", "childLabel": "Site"}}}
+}
+`
+	path := writeSchemaFile(t, content)
+
+	doc, err := LoadStatic(path)
+	if err != nil {
+		t.Fatalf("LoadStatic: %v", err)
+	}
+	if doc.Path != path || doc.JSON() != strings.TrimSpace(content) {
+		t.Fatalf("expected the file's document, got path=%q", doc.Path)
+	}
+	g := doc.Graph()
+	if !slices.Equal(g.VertexLabels, []string{"Site", "Trial"}) || len(g.Vertices["Site"].Properties) != 2 {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+}
+
+func TestLoadStaticReportsValidationProblems(t *testing.T) {
+	path := writeSchemaFile(t, `{
+  "root_label": "Trail",
+  "properties": {"all_vertices": ["id"], "Site": ["city", "id", ""], "Sites": ["x"]},
+  "known_instance_types": {"Study": ["A"]},
+  "schema": {"Trial": {"sites": {"edgeLabel": "", "childLabel": "Site"}}}
+}`)
+
+	_, err := LoadStatic(path)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{
+		`schema.Trial.sites: edgeLabel is required`,
+		`root_label: "Trail" is not a vertex label in schema`,
+		`properties.Site[1]: duplicate property "id"`,
+		`properties.Site[2]: property name is empty`,
+		`properties.Sites: "Sites" is not a vertex label in schema`,
+		`known_instance_types.Study: "Study" is not a vertex label in schema`,
+	}
+	if !slices.Equal(validationErr.Problems, want) {
+		t.Fatalf("unexpected problems:\n%s", strings.Join(validationErr.Problems, "\n"))
+	}
+	if !strings.HasPrefix(err.Error(), "invalid static schema "+path+":\n  - ") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
+
+func TestLoadStaticReportsDecodeErrorPositions(t *testing.T) {
+	for _, tc := range []struct {
+		name, content, want string
+	}{
+		{"syntax", "{\n  \"root_label\": \"Study\",\n  \"schema\": {,}\n}", "line 3, column 14: invalid character ','"},
+		{"type", "{\n  \"root_label\": [\"Study\"]\n}", "line 2, column 17: root_label must be string, got array"},
+		{"unknown field", `{"root_lable": "Study"}`, `unknown field "root_lable"`},
+		{"trailing data", `{"root_label": "Study", "schema": {"Study": {}}} {}`, "unexpected data after the schema document"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadStatic(writeSchemaFile(t, tc.content))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q in error, got %v", tc.want, err)
+			}
+		})
+	}
+}
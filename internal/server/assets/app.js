@@ -26,10 +26,13 @@ document.addEventListener("DOMContentLoaded", () => {
     ? wrapResultContainer(resultContainer)
     : null;
   const errorMessage = document.querySelector('[data-role="error"]');
+  const warningMessage = document.querySelector('[data-role="warning"]');
   const copyButton = document.querySelector('[data-role="copy"]');
   const queryTypeField = document.getElementById("query-type");
   const submitButton = document.querySelector('[data-role="submit"]');
+  const formButtons = form.querySelectorAll('button[type="submit"]');
   const queryField = document.getElementById("query-text");
+  const paramsField = document.getElementById("query-params");
   const editor = document.querySelector(".editor");
   const highlightOverlay = editor?.querySelector(".highlight");
   const themeToggle = document.querySelector('[data-role="theme-toggle"]');
@@ -148,6 +151,20 @@ document.addEventListener("DOMContentLoaded", () => {
     rootElement?.setAttribute("data-editor-ready", "true");
   }
 
+  // Saved queries from the library sidebar load into the form with their
+  // default parameters.
+  document.querySelectorAll('[data-role="saved-query"]').forEach((item) => {
+    item.addEventListener("click", () => {
+      queryTypeField.value = item.dataset.type;
+      queryField.value = item.dataset.query;
+      queryField.dispatchEvent(new Event("input"));
+      if (paramsField) {
+        paramsField.value = item.dataset.params ?? "";
+      }
+      queryField.focus();
+    });
+  });
+
   function wrapResultContainer(container) {
     if (!container || !container.parentElement) {
       return null;
@@ -196,20 +213,39 @@ document.addEventListener("DOMContentLoaded", () => {
   form.addEventListener("submit", async (event) => {
     event.preventDefault();
 
+    // Explain and Profile submit the form too; their data-mode asks the
+    // server for Neptune's plan instead of the result.
+    const activeButton = event.submitter ?? submitButton;
+    const mode = activeButton.dataset.mode;
     const payload = {
       type: queryTypeField.value,
       query: queryField.value,
     };
+    if (mode) {
+      payload.mode = mode;
+    }
+    if (paramsField?.value.trim()) {
+      try {
+        payload.params = JSON.parse(paramsField.value);
+      } catch (error) {
+        errorMessage.textContent = `Parameters must be a JSON object: ${error.message}`;
+        errorMessage.hidden = false;
+        return;
+      }
+    }
 
     console.log("Submitting payload:", payload);
 
     errorMessage.hidden = true;
-    submitButton.textContent = "Running...";
-    submitButton.disabled = true;
-    submitButton.setAttribute("aria-busy", "true");
+    showWarnings(null);
+    activeButton.textContent = "Running...";
+    formButtons.forEach((button) => {
+      button.disabled = true;
+    });
+    activeButton.setAttribute("aria-busy", "true");
     resultContent.setAttribute("aria-busy", "true");
     showSpinnerOverlay();
-    flashButton(submitButton, "btn-flash");
+    flashButton(activeButton, "btn-flash");
 
     try {
       const response = await fetch("/queries", {
@@ -219,9 +255,10 @@ document.addEventListener("DOMContentLoaded", () => {
       });
 
       var data = await response.json();
+      showWarnings(data);
 
       if (!response.ok) {
-        throw new Error(data.error || "Request failed");
+        throw new Error(formatErrors(data) || "Request failed");
       }
 
       const processed = data.processed || "(empty response)";
@@ -229,7 +266,9 @@ document.addEventListener("DOMContentLoaded", () => {
       hideSpinnerOverlay();
       resultContent.removeAttribute("data-highlighted");
       resultContent.classList.remove("hljs");
-      if (processed.length <= MAX_HIGHLIGHT_LENGTH) {
+      if (data.mode) {
+        // Plans are mostly plain text; JSON highlighting would garble them.
+      } else if (processed.length <= MAX_HIGHLIGHT_LENGTH) {
         const highlighted = safeHighlightElement(resultContent);
         if (!highlighted) {
           resultContent.textContent = processed;
@@ -248,9 +287,11 @@ document.addEventListener("DOMContentLoaded", () => {
       errorMessage.hidden = false;
       resultContent.textContent = JSON.stringify(data, null, 2);
     } finally {
-      submitButton.disabled = false;
-      submitButton.textContent = "Run";
-      submitButton.removeAttribute("aria-busy");
+      formButtons.forEach((button) => {
+        button.disabled = false;
+      });
+      activeButton.textContent = activeButton.dataset.label;
+      activeButton.removeAttribute("aria-busy");
       resultContent.removeAttribute("aria-busy");
       hideSpinnerOverlay();
     }
@@ -278,6 +319,33 @@ document.addEventListener("DOMContentLoaded", () => {
     }
   });
 
+  // Schema lint warnings: names in the query that the graph schema does not
+  // contain. The query still ran, so they are shown next to the result, as
+  // is a note when the result came from the server's result cache.
+  function showWarnings(data) {
+    if (!warningMessage) {
+      return;
+    }
+    const warnings = Array.isArray(data?.warnings) ? data.warnings : [];
+    const notes = warnings.map((warning) => warning.message);
+    if (data?.cached) {
+      notes.unshift("Served from the result cache.");
+    }
+    warningMessage.textContent = notes.join("\n");
+    warningMessage.hidden = notes.length === 0;
+  }
+
+  function formatErrors(data) {
+    if (Array.isArray(data?.errors) && data.errors.length > 0) {
+      return data.errors
+        .map((err) =>
+          err.errorType ? `${err.errorType}: ${err.message}` : err.message,
+        )
+        .join("\n");
+    }
+    return data?.error;
+  }
+
   function subtleScroll(element) {
     //scrollTop: Current vertical scroll position of the element.
     //clientHeight: Visible height of the element (viewport).
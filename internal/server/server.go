@@ -10,6 +10,13 @@ import (
 	"net/http"
 	"time"
 
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/library"
+	"github.com/ankit-lilly/nqcli/internal/lint"
+	"github.com/ankit-lilly/nqcli/internal/params"
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/ankit-lilly/nqcli/internal/safety"
+
 	"github.com/charmbracelet/log"
 )
 
@@ -34,21 +41,52 @@ var (
 )
 
 type queryExecutor interface {
-	ExecuteQuery(string, string) (string, string, error)
+	ExecuteQueryContext(context.Context, string, string) (*result.QueryResult, error)
+}
+
+// queryExplainer is implemented by executors that can return Neptune's
+// explain or profile output for a query.
+type queryExplainer interface {
+	ExplainQueryContext(ctx context.Context, query, queryType string, mode neptune.Mode) (*result.QueryResult, error)
 }
 
 type Server struct {
-	app    queryExecutor
-	logger *log.Logger
-	mux    *http.ServeMux
+	app          queryExecutor
+	logger       *log.Logger
+	mux          *http.ServeMux
+	lint         func(query, queryType string) []lint.Warning
+	savedQueries func() ([]library.Query, []error, error)
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithLinter checks each query with check before it runs and returns the
+// warnings alongside the result.
+func WithLinter(check func(query, queryType string) []lint.Warning) Option {
+	return func(s *Server) {
+		s.lint = check
+	}
 }
 
-func New(appService queryExecutor, logger *log.Logger) *Server {
+// WithLibrary lists the queries returned by list in a sidebar; choosing one
+// loads it, with its default parameters, into the query form. Problems with
+// individual saved query files are logged.
+func WithLibrary(list func() ([]library.Query, []error, error)) Option {
+	return func(s *Server) {
+		s.savedQueries = list
+	}
+}
+
+func New(appService queryExecutor, logger *log.Logger, opts ...Option) *Server {
 	s := &Server{
 		app:    appService,
 		logger: logger,
 		mux:    http.NewServeMux(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
 
 	s.routes()
 
@@ -107,15 +145,23 @@ func (s *Server) handleHealthz() http.HandlerFunc {
 
 func (s *Server) handleExecuteQuery() http.HandlerFunc {
 	type queryRequest struct {
-		Type  string `json:"type"`
-		Query string `json:"query"`
+		Type   string         `json:"type"`
+		Query  string         `json:"query"`
+		Params map[string]any `json:"params,omitempty"`
+		Mode   string         `json:"mode,omitempty"`
 	}
 
 	type queryResponse struct {
-		Type         string `json:"type"`
-		Processed    string `json:"processed"`
-		RawResponse  string `json:"rawResponse"`
-		ErrorMessage string `json:"error,omitempty"`
+		Type         string                  `json:"type"`
+		Mode         neptune.Mode            `json:"mode,omitempty"`
+		Processed    string                  `json:"processed"`
+		RawResponse  string                  `json:"rawResponse"`
+		DurationMs   int64                   `json:"durationMs,omitempty"`
+		RequestID    string                  `json:"requestId,omitempty"`
+		Cached       bool                    `json:"cached,omitempty"`
+		ErrorMessage string                  `json:"error,omitempty"`
+		Errors       []*neptune.GraphQLError `json:"errors,omitempty"`
+		Warnings     []lint.Warning          `json:"warnings,omitempty"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -128,7 +174,9 @@ func (s *Server) handleExecuteQuery() http.HandlerFunc {
 		r.Body = http.MaxBytesReader(w, r.Body, requestBodyLimit)
 
 		var req queryRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&req); err != nil {
 			http.Error(w, "invalid JSON payload", http.StatusBadRequest)
 			return
 		}
@@ -138,17 +186,56 @@ func (s *Server) handleExecuteQuery() http.HandlerFunc {
 			queryType = defaultQueryType
 		}
 
-		processed, raw, err := s.app.ExecuteQuery(req.Query, queryType)
+		mode, err := neptune.ParseMode(req.Mode)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query, err := params.Render(req.Query, queryType, req.Params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var res *result.QueryResult
+		if mode == neptune.ModeExecute {
+			res, err = s.app.ExecuteQueryContext(r.Context(), query, queryType)
+		} else if explainer, ok := s.app.(queryExplainer); ok {
+			res, err = explainer.ExplainQueryContext(r.Context(), query, queryType, mode)
+		} else {
+			http.Error(w, string(mode)+" is not supported by this server", http.StatusNotImplemented)
+			return
+		}
 		resp := queryResponse{
-			Type:        queryType,
-			Processed:   processed,
-			RawResponse: raw,
+			Type: queryType,
+			Mode: mode,
+		}
+		if res != nil {
+			resp.RawResponse = res.Raw
+			resp.DurationMs = res.Duration.Milliseconds()
+			resp.RequestID = res.RequestID
+			resp.Cached = !res.CachedAt.IsZero()
+			if err == nil {
+				resp.Processed = res.JSON()
+			}
+		}
+		if s.lint != nil {
+			resp.Warnings = s.lint(query, queryType)
 		}
 
 		status := http.StatusOK
 		if err != nil {
 			resp.ErrorMessage = err.Error()
 			status = http.StatusBadRequest
+			if errors.Is(err, safety.ErrReadOnly) {
+				status = http.StatusForbidden
+			}
+
+			var respErr *neptune.ResponseError
+			if errors.As(err, &respErr) {
+				resp.Errors = respErr.Errors
+			}
 		}
 
 		w.Header().Set("Content-Type", contentTypeJSON)
@@ -159,6 +246,17 @@ func (s *Server) handleExecuteQuery() http.HandlerFunc {
 	}
 }
 
+// savedQueryView is a saved query as the sidebar renders it, with its
+// default parameters as the JSON the parameters field takes.
+type savedQueryView struct {
+	library.Query
+	ParamsJSON string
+}
+
+type indexPage struct {
+	SavedQueries []savedQueryView
+}
+
 func (s *Server) handleIndex() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -166,8 +264,29 @@ func (s *Server) handleIndex() http.HandlerFunc {
 			return
 		}
 
+		var page indexPage
+		if s.savedQueries != nil {
+			queries, problems, err := s.savedQueries()
+			if err != nil {
+				// The page is still usable without the sidebar.
+				s.logger.Error("failed to list saved queries", "error", err)
+			}
+			for _, problem := range problems {
+				s.logger.Warn("skipping saved query", "error", problem)
+			}
+			for _, q := range queries {
+				view := savedQueryView{Query: q}
+				if len(q.Params) > 0 {
+					if data, err := json.Marshal(q.Params); err == nil {
+						view.ParamsJSON = string(data)
+					}
+				}
+				page.SavedQueries = append(page.SavedQueries, view)
+			}
+		}
+
 		w.Header().Set("Content-Type", contentTypeHTML)
-		if err := pageTemplates.ExecuteTemplate(w, "index", nil); err != nil {
+		if err := pageTemplates.ExecuteTemplate(w, "index", page); err != nil {
 			s.logger.Error("failed to render template", "error", err)
 		}
 	}
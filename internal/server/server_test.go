@@ -1,12 +1,20 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	neptune "github.com/ankit-lilly/nqcli/internal/gq"
+	"github.com/ankit-lilly/nqcli/internal/library"
+	"github.com/ankit-lilly/nqcli/internal/lint"
+	"github.com/ankit-lilly/nqcli/internal/result"
+	"github.com/ankit-lilly/nqcli/internal/safety"
+
 	"github.com/charmbracelet/log"
 )
 
@@ -14,13 +22,26 @@ type spyExecutor struct {
 	called    bool
 	lastQuery string
 	lastType  string
+	lastMode  neptune.Mode
+	err       error
+}
+
+func (s *spyExecutor) ExecuteQueryContext(_ context.Context, query, queryType string) (*result.QueryResult, error) {
+	s.called = true
+	s.lastQuery = query
+	s.lastType = queryType
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &result.QueryResult{Text: "processed", Raw: "raw"}, nil
 }
 
-func (s *spyExecutor) ExecuteQuery(query, queryType string) (string, string, error) {
+func (s *spyExecutor) ExplainQueryContext(_ context.Context, query, queryType string, mode neptune.Mode) (*result.QueryResult, error) {
 	s.called = true
 	s.lastQuery = query
 	s.lastType = queryType
-	return "processed", "raw", nil
+	s.lastMode = mode
+	return &result.QueryResult{Text: "plan", Raw: "raw"}, nil
 }
 
 func TestQueriesEndpointInvokesExecutor(t *testing.T) {
@@ -49,3 +70,166 @@ func TestQueriesEndpointInvokesExecutor(t *testing.T) {
 		t.Fatalf("expected type 'gremlin', got %q", executor.lastType)
 	}
 }
+
+func TestQueriesEndpointRendersParams(t *testing.T) {
+	t.Parallel()
+
+	executor := &spyExecutor{}
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	srv := New(executor, logger)
+
+	body := `{"type":"cypher","query":"MATCH (n {name: $name}) RETURN n LIMIT $limit","params":{"name":"O'Brien","limit":5}}`
+	req := httptest.NewRequest(http.MethodPost, "/queries", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	want := `MATCH (n {name: 'O\'Brien'}) RETURN n LIMIT 5`
+	if executor.lastQuery != want {
+		t.Fatalf("expected query %q, got %q", want, executor.lastQuery)
+	}
+}
+
+func TestQueriesEndpointRejectsMissingParams(t *testing.T) {
+	t.Parallel()
+
+	executor := &spyExecutor{}
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	srv := New(executor, logger)
+
+	body := `{"query":"g.V().has('name', $name)","params":{"other":1}}`
+	req := httptest.NewRequest(http.MethodPost, "/queries", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if executor.called {
+		t.Fatalf("expected executor not to be called")
+	}
+}
+
+func TestQueriesEndpointReportsReadOnlyAsForbidden(t *testing.T) {
+	t.Parallel()
+
+	executor := &spyExecutor{err: safety.Check("g.V().drop()", "gremlin")}
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	srv := New(executor, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/queries", strings.NewReader(`{"query":"g.V().drop()"}`))
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestQueriesEndpointReturnsLintWarnings(t *testing.T) {
+	t.Parallel()
+
+	executor := &spyExecutor{}
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	var linted string
+	srv := New(executor, logger, WithLinter(func(query, queryType string) []lint.Warning {
+		linted = query
+		return []lint.Warning{{Name: "Stdy", Message: `unknown vertex label "Stdy"`}}
+	}))
+
+	body := `{"type":"gremlin","query":"g.V().hasLabel($label)","params":{"label":"Stdy"}}`
+	req := httptest.NewRequest(http.MethodPost, "/queries", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if linted != "g.V().hasLabel('Stdy')" {
+		t.Fatalf("expected the rendered query to be linted, got %q", linted)
+	}
+	if !strings.Contains(rec.Body.String(), `"warnings":[{`) || !strings.Contains(rec.Body.String(), `unknown vertex label`) {
+		t.Fatalf("expected warnings in response, got %s", rec.Body.String())
+	}
+}
+
+func TestQueriesEndpointExplainsQuery(t *testing.T) {
+	t.Parallel()
+
+	executor := &spyExecutor{}
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	srv := New(executor, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/queries", strings.NewReader(`{"type":"gremlin","query":"g.V()","mode":"explain"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if executor.lastMode != neptune.ModeExplain {
+		t.Fatalf("expected mode %q, got %q", neptune.ModeExplain, executor.lastMode)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"mode":"explain"`) || !strings.Contains(body, `"processed":"plan"`) {
+		t.Fatalf("expected the plan in the response, got %s", body)
+	}
+}
+
+func TestQueriesEndpointRejectsUnknownMode(t *testing.T) {
+	t.Parallel()
+
+	executor := &spyExecutor{}
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	srv := New(executor, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/queries", strings.NewReader(`{"type":"gremlin","query":"g.V()","mode":"trace"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if executor.called {
+		t.Fatalf("expected the executor not to be called")
+	}
+}
+
+func TestIndexListsSavedQueries(t *testing.T) {
+	t.Parallel()
+
+	logger := log.NewWithOptions(io.Discard, log.Options{})
+	srv := New(&spyExecutor{}, logger, WithLibrary(func() ([]library.Query, []error, error) {
+		return []library.Query{{
+			Name:        "study-versions",
+			Description: "Versions of a study",
+			Language:    "gremlin",
+			Query:       "g.V().has('Study', 'name', $study).limit($limit)",
+			Params:      map[string]any{"limit": 10},
+		}}, []error{errors.New("broken.yaml: bad YAML")}, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"study-versions", "Versions of a study", `data-params="{&#34;limit&#34;:10}"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the page, got:\n%s", want, body)
+		}
+	}
+}